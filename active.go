@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// activeSession is a session triggered from this machine, remembered so
+// that asking again for the same resource reattaches to it instead of
+// starting a duplicate.
+type activeSession struct {
+	SessionID   string     `json:"sessionId"`
+	TriggeredAt time.Time  `json:"triggeredAt"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+}
+
+// activeSessions maps resourceKey to the latest session for that resource.
+type activeSessions map[string]activeSession
+
+func activeSessionsPath() (string, error) {
+	dir, err := appDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "active_sessions.json"), nil
+}
+
+// resourceKey identifies a resource across invocations.
+func resourceKey(config *Config) string {
+	return strings.Join([]string{config.LocalClusterName, config.Namespace, strings.ToLower(config.Kind), config.Name}, "/")
+}
+
+func loadActiveSessions() (activeSessions, error) {
+	path, err := activeSessionsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return activeSessions{}, nil
+		}
+		return nil, err
+	}
+
+	sessions := activeSessions{}
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to parse active sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// saveActiveSessions writes the state file, dropping sessions too old to be
+// reattached to under any window.
+func saveActiveSessions(sessions activeSessions) error {
+	path, err := activeSessionsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	for key, session := range sessions {
+		if time.Since(session.TriggeredAt) > 24*time.Hour {
+			delete(sessions, key)
+		}
+	}
+
+	data, err := json.MarshalIndent(sessions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal active sessions: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write active sessions: %w", err)
+	}
+	return nil
+}
+
+// updateActiveSessions applies fn to the state file and saves it. Failures
+// are only logged; the state file is a convenience.
+func updateActiveSessions(fn func(activeSessions)) {
+	sessions, err := loadActiveSessions()
+	if err != nil {
+		logMessage("⚠️  Could not read active sessions, starting afresh: %v", err)
+		sessions = activeSessions{}
+	}
+	fn(sessions)
+	if err := saveActiveSessions(sessions); err != nil {
+		logMessage("⚠️  Could not save active sessions: %v", err)
+	}
+}
+
+func recordActiveSession(config *Config, sessionID string) {
+	updateActiveSessions(func(sessions activeSessions) {
+		sessions[resourceKey(config)] = activeSession{SessionID: sessionID, TriggeredAt: time.Now()}
+	})
+}
+
+// markActiveSessionFinished notes when a session finished, which starts its
+// reattach window. Cancelled sessions are forgotten right away.
+func markActiveSessionFinished(sessionID string, cancelled bool) {
+	updateActiveSessions(func(sessions activeSessions) {
+		for key, session := range sessions {
+			if session.SessionID != sessionID {
+				continue
+			}
+			if cancelled {
+				delete(sessions, key)
+				continue
+			}
+			if session.FinishedAt == nil {
+				now := time.Now()
+				session.FinishedAt = &now
+				sessions[key] = session
+			}
+		}
+	})
+}
+
+// resumeCheckTimeout bounds the request that checks a session before
+// reattaching, which runs before anything is shown.
+const resumeCheckTimeout = 5 * time.Second
+
+// findResumableSession returns the session to reattach to for the configured
+// resource: one that is still running, or, only when config.ReuseWindow is
+// set, one that completed within it. The server has the final say on the
+// session's state.
+func findResumableSession(ctx context.Context, config *Config) (string, bool) {
+	sessions, err := loadActiveSessions()
+	if err != nil {
+		logMessage("⚠️  Could not read active sessions: %v", err)
+		return "", false
+	}
+
+	session, ok := sessions[resourceKey(config)]
+	if !ok || time.Since(session.TriggeredAt) > monitorTimeout+config.ReuseWindow {
+		return "", false
+	}
+	if session.FinishedAt != nil && time.Since(*session.FinishedAt) > config.ReuseWindow {
+		return "", false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, resumeCheckTimeout)
+	defer cancel()
+	results, err := config.Client.GetSession(ctx, session.SessionID)
+	if err != nil {
+		logMessage("Not reattaching to session %s: %v", session.SessionID, err)
+		return "", false
+	}
+
+	switch {
+	case results.IsFailed:
+		return "", false
+	case results.IsComplete:
+		// Without a recorded finish time, the session finished some time
+		// after it was triggered.
+		finishedAt := session.TriggeredAt
+		if session.FinishedAt != nil {
+			finishedAt = *session.FinishedAt
+		}
+		if time.Since(finishedAt) > config.ReuseWindow {
+			return "", false
+		}
+	}
+	return session.SessionID, true
+}
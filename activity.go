@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k9s-rca/komodor"
+)
+
+// highlightDuration is how long newly discovered items stay highlighted.
+const highlightDuration = 5 * time.Second
+
+// maxActivity caps the activity feed kept on the model.
+const maxActivity = 20
+
+// resultChange is what one poll added on top of the previous one.
+type resultChange struct {
+	Operations     int
+	WhatHappened   int
+	Evidence       int
+	Problem        bool
+	Recommendation bool
+	Status         string
+}
+
+func diffResults(prev, next *komodor.RCAPollResponse) resultChange {
+	change := resultChange{
+		Operations:     max(len(next.Operations)-len(prev.Operations), 0),
+		WhatHappened:   max(len(next.WhatHappened)-len(prev.WhatHappened), 0),
+		Evidence:       max(len(next.EvidenceCollection)-len(prev.EvidenceCollection), 0),
+		Problem:        next.ProblemShort != prev.ProblemShort && next.ProblemShort != "",
+		Recommendation: next.Recommendation != prev.Recommendation && next.Recommendation != "",
+	}
+	if state := sessionState(next); state != sessionState(prev) {
+		change.Status = state
+	}
+	return change
+}
+
+func (c resultChange) Empty() bool {
+	return c == resultChange{}
+}
+
+// String summarizes the change, e.g. "+2 evidence, recommendation updated".
+func (c resultChange) String() string {
+	var parts []string
+	if c.Operations > 0 {
+		parts = append(parts, fmt.Sprintf("+%d %s", c.Operations, plural(c.Operations, "operation", "operations")))
+	}
+	if c.WhatHappened > 0 {
+		parts = append(parts, fmt.Sprintf("+%d timeline %s", c.WhatHappened, plural(c.WhatHappened, "entry", "entries")))
+	}
+	if c.Evidence > 0 {
+		parts = append(parts, fmt.Sprintf("+%d evidence", c.Evidence))
+	}
+	if c.Problem {
+		parts = append(parts, "problem updated")
+	}
+	if c.Recommendation {
+		parts = append(parts, "recommendation updated")
+	}
+	if c.Status != "" {
+		parts = append(parts, "now "+c.Status)
+	}
+	return strings.Join(parts, ", ")
+}
+
+type activityEntry struct {
+	at   time.Time
+	text string
+}
+
+// itemAges records when each list item was first seen, so new ones can be
+// highlighted. Items already present on the first poll have a zero time.
+type itemAges struct {
+	operations   []time.Time
+	whatHappened []time.Time
+	evidence     []time.Time
+}
+
+func (a *itemAges) track(results *komodor.RCAPollResponse, seen time.Time) {
+	a.operations = trackAges(a.operations, len(results.Operations), seen)
+	a.whatHappened = trackAges(a.whatHappened, len(results.WhatHappened), seen)
+	a.evidence = trackAges(a.evidence, len(results.EvidenceCollection), seen)
+}
+
+func trackAges(ages []time.Time, count int, seen time.Time) []time.Time {
+	if count < len(ages) {
+		return ages[:count]
+	}
+	for len(ages) < count {
+		ages = append(ages, seen)
+	}
+	return ages
+}
+
+// isNew reports whether item i of ages was seen within highlightDuration.
+func isNew(ages []time.Time, i int) bool {
+	return i < len(ages) && !ages[i].IsZero() && time.Since(ages[i]) < highlightDuration
+}
+
+func countNew(ages []time.Time) int {
+	n := 0
+	for i := range ages {
+		if isNew(ages, i) {
+			n++
+		}
+	}
+	return n
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// formatDuration renders short durations for the TUI, e.g. 45s or 3m05s.
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
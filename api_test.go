@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k9s-rca/komodor"
+)
+
+// recordingTUI keeps the final results pollRCAResults displays.
+type recordingTUI struct {
+	final *komodor.RCAPollResponse
+}
+
+func (r *recordingTUI) ClearScreen()                                        {}
+func (r *recordingTUI) DisplayLiveRCAResults(*komodor.RCAPollResponse, int) {}
+func (r *recordingTUI) DisplayFinalRCAResults(res *komodor.RCAPollResponse) { r.final = res }
+func (r *recordingTUI) DisplayError(string, error)                          {}
+func (r *recordingTUI) DisplayMessage(string)                               {}
+func (r *recordingTUI) DisplayProgressIndicator(string)                     {}
+func (r *recordingTUI) WaitForExit()                                        {}
+
+func TestPollRCAResultsStreamFallback(t *testing.T) {
+	const pollInterval = 20 * time.Millisecond
+
+	tests := []struct {
+		name      string
+		stream    func(w http.ResponseWriter, r *http.Request)
+		wantPolls bool
+	}{
+		{
+			name:      "stream not offered",
+			stream:    func(w http.ResponseWriter, r *http.Request) { http.NotFound(w, r) },
+			wantPolls: true,
+		},
+		{
+			name: "not an event stream",
+			stream: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{}`)
+			},
+			wantPolls: true,
+		},
+		{
+			name: "stream goes idle",
+			stream: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, "data: {\"sessionId\":\"s1\",\"operations\":[\"started\"]}\n\n")
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+			},
+			wantPolls: true,
+		},
+		{
+			name: "stream delivers the result",
+			stream: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, "data: {\"sessionId\":\"s1\",\"isComplete\":true,\"problemShort\":\"OOMKilled\"}\n\n")
+			},
+			wantPolls: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+
+			var polls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, "/stream") {
+					tt.stream(w, r)
+					return
+				}
+				polls.Add(1)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"sessionId":"s1","isComplete":true,"problemShort":"OOMKilled"}`)
+			}))
+			defer server.Close()
+
+			tui := &recordingTUI{}
+			config := &Config{
+				KomodorBaseURL: server.URL,
+				KomodorAPIKey:  "key",
+				PollInterval:   pollInterval,
+				Retry:          komodor.DefaultRetryPolicy(),
+				Stream:         true,
+				TUI:            tui,
+			}
+			config.Client = newKomodorClient(config)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := pollRCAResults(ctx, config, "s1"); err != nil {
+				t.Fatalf("pollRCAResults() error = %v", err)
+			}
+			if tui.final == nil || tui.final.ProblemShort != "OOMKilled" {
+				t.Errorf("final results = %+v, want the completed session", tui.final)
+			}
+			if got := polls.Load() > 0; got != tt.wantPolls {
+				t.Errorf("polled = %t (%d requests), want %t", got, polls.Load(), tt.wantPolls)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type sessionCancelledMsg struct {
+	err error
+}
+
+func cancelSessionCmd(ctx context.Context, config *Config, sessionID string) tea.Cmd {
+	return func() tea.Msg {
+		return sessionCancelledMsg{err: config.Client.CancelSession(ctx, sessionID)}
+	}
+}
+
+// canStopSession reports whether quitting should first ask what to do with
+// the session on the server.
+func (m rcaModel) canStopSession() bool {
+	return !m.finished() && !m.retriggering && m.config.Client != nil
+}
+
+// quit leaves the TUI. Leaving an unfinished session cancels the monitor
+// with the reason, which decides the exit code; choosing to keep it running
+// in the background is not an error, even when it is stuck.
+func (m rcaModel) quit() (rcaModel, tea.Cmd) {
+	m.quitting = true
+	switch {
+	case m.stopped:
+		m.cancel(fmt.Errorf("%w: session %s stopped on the server", errSessionCancelled, m.sessionID))
+	case m.detached:
+	case m.stuckPrompt:
+		m.cancel(fmt.Errorf("%w: session %s", errSessionStuck, m.sessionID))
+	case !m.finished():
+		m.cancel(errInterrupted)
+	}
+	return m, tea.Quit
+}
+
+// answerQuitPrompt handles the keys of the prompt shown when quitting a
+// running session.
+func (m rcaModel) answerQuitPrompt(msg tea.KeyMsg) (rcaModel, tea.Cmd) {
+	if m.stopping {
+		return m, nil
+	}
+
+	keys := m.keyMap()
+	switch {
+	case key.Matches(msg, keys.StopSession):
+		logMessage("Stopping RCA session %s on the server", m.sessionID)
+		m.stopping = true
+		m.notice = ""
+		return m, cancelSessionCmd(m.ctx, m.config, m.sessionID)
+	case key.Matches(msg, keys.Detach):
+		logMessage("Leaving RCA session %s running in the background", m.sessionID)
+		m.detached = true
+		recordSessionOutcome(m.sessionID, "detached")
+		return m.quit()
+	case key.Matches(msg, keys.Stay):
+		m.quitPrompt = false
+		return m, nil
+	}
+	return m, nil
+}
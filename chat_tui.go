@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// chatChunkMsg and chatDoneMsg carry the stream they came from, so that
+// pieces of an answer that was abandoned since can be told apart.
+type chatChunkMsg struct {
+	stream <-chan tea.Msg
+	text   string
+}
+
+type chatDoneMsg struct {
+	stream <-chan tea.Msg
+	answer string
+	err    error
+}
+
+var (
+	chatUserStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("117")).
+			MarginTop(1)
+
+	chatAssistantStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("170")).
+				MarginTop(1)
+)
+
+func newChatInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = "💬 "
+	input.Placeholder = "Press Enter to ask a follow-up question"
+	input.CharLimit = 2000
+	return input
+}
+
+// askCmd streams the answer to a follow-up question until stop is called.
+// The first message is returned by the command itself; waitForChat reads
+// the rest.
+func askCmd(ctx context.Context, config *Config, sessionID, question string) (_ <-chan tea.Msg, stop context.CancelFunc, _ tea.Cmd) {
+	ctx, stop = context.WithCancel(ctx)
+	ch := make(chan tea.Msg)
+	send := func(msg tea.Msg) {
+		select {
+		case ch <- msg:
+		case <-ctx.Done():
+		}
+	}
+
+	return ch, stop, func() tea.Msg {
+		go func() {
+			defer close(ch)
+			answer, err := config.Client.AskFollowUp(ctx, sessionID, question, func(chunk string) {
+				send(chatChunkMsg{stream: ch, text: chunk})
+			})
+			send(chatDoneMsg{stream: ch, answer: answer, err: err})
+		}()
+		return <-ch
+	}
+}
+
+func waitForChat(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
+
+// canChat reports whether follow-up questions can be asked: the session
+// must be complete and there must be an API key to ask with.
+func (m rcaModel) canChat() bool {
+	return m.isComplete && m.config.Client != nil && m.config.KomodorAPIKey != ""
+}
+
+func (m rcaModel) sendQuestion() (rcaModel, tea.Cmd) {
+	question := strings.TrimSpace(m.chatInput.Value())
+	m.chatInput.Reset()
+	m.chatInput.Blur()
+	if question == "" {
+		return m, nil
+	}
+
+	logMessage("Asking follow-up on session %s: %s", m.sessionID, question)
+	message := ChatMessage{Role: chatRoleUser, Content: question, At: time.Now()}
+	m.chat = append(m.chat, message)
+	recordChatMessage(m.sessionID, message)
+
+	m.chatting = true
+	m.chatPending = ""
+	m.notice = ""
+	var cmd tea.Cmd
+	m.chatStream, m.stopChat, cmd = askCmd(m.ctx, m.config, m.sessionID, question)
+	m.followChat()
+	return m, cmd
+}
+
+// closeChat abandons the answer being streamed, if any.
+func (m *rcaModel) closeChat() {
+	if m.stopChat != nil {
+		m.stopChat()
+	}
+	m.chatting = false
+	m.chatPending = ""
+	m.chatStream, m.stopChat = nil, nil
+}
+
+// savePartialAnswer records the part of an answer that had arrived when the
+// TUI was left mid-answer.
+func (m rcaModel) savePartialAnswer() {
+	if !m.chatting || m.chatPending == "" {
+		return
+	}
+	logMessage("Saving partial follow-up answer on session %s", m.sessionID)
+	recordChatMessage(m.sessionID, ChatMessage{Role: chatRoleAssistant, Content: m.chatPending, At: time.Now(), Interrupted: true})
+}
+
+// followChat keeps the conversation scrolled to the newest message.
+func (m *rcaModel) followChat() {
+	if m.activeTab == tabChat {
+		m.syncViewport()
+		m.viewport.GotoBottom()
+	}
+}
+
+func (m rcaModel) chatView(width int) string {
+	var s strings.Builder
+
+	item := wrapTo(itemStyle, width)
+	hint := wrapTo(itemStyle.Foreground(lipgloss.Color("241")), width)
+
+	if len(m.chat) == 0 && !m.chatting {
+		switch {
+		case m.canChat():
+			s.WriteString(hint.Render(`💬 Ask Komodor a follow-up question about this RCA, e.g. "why did the probe fail?" or "what changed in the config?". Press Enter to start typing.`))
+		case !m.isComplete:
+			s.WriteString(hint.Render("💬 Follow-up questions are available once the analysis completes."))
+		default:
+			s.WriteString(hint.Render("💬 Follow-up questions need a Komodor API key (set KOMODOR_API_KEY or --api-key)."))
+		}
+		return s.String()
+	}
+
+	for _, message := range m.chat {
+		s.WriteString(chatHeader(message.Role, message.At))
+		s.WriteString("\n")
+		content := message.Content
+		if message.Interrupted {
+			content += labelStyle.Render(" (interrupted)")
+		}
+		s.WriteString(item.Render(content))
+		s.WriteString("\n")
+	}
+
+	if m.chatting {
+		s.WriteString(chatHeader(chatRoleAssistant, time.Time{}))
+		s.WriteString("\n")
+		if m.chatPending == "" {
+			s.WriteString(item.Render(labelStyle.Render("⏳ Thinking...")))
+		} else {
+			s.WriteString(item.Render(m.chatPending + "▍"))
+		}
+		s.WriteString("\n")
+	}
+
+	return strings.TrimSuffix(s.String(), "\n")
+}
+
+func chatHeader(role string, at time.Time) string {
+	header := chatAssistantStyle.Render("🤖 Komodor")
+	if role == chatRoleUser {
+		header = chatUserStyle.Render("🧑 You")
+	}
+	if !at.IsZero() {
+		header += labelStyle.Render(fmt.Sprintf("  %s", at.Local().Format("15:04:05")))
+	}
+	return header
+}
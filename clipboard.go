@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type clipboardMsg struct {
+	what string
+	err  error
+}
+
+// copyToClipboard sets the terminal clipboard with an OSC52 escape sequence,
+// which also works over SSH and inside k9s. tmux and screen need the
+// sequence wrapped to pass it through to the outer terminal.
+func copyToClipboard(text string) error {
+	seq := osc52.New(text)
+	switch {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		seq = seq.Screen()
+	}
+	_, err := seq.WriteTo(os.Stderr)
+	return err
+}
+
+func copyCmd(what, text string) tea.Cmd {
+	return func() tea.Msg {
+		err := copyToClipboard(text)
+		if err != nil {
+			logMessage("ERROR: Failed to copy %s to clipboard: %v", what, err)
+		}
+		return clipboardMsg{what: what, err: err}
+	}
+}
@@ -1,27 +1,39 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"k9s-rca/komodor"
 )
 
+// ClusterMapping is ~/.k9s-komodor-rca/clusters.yaml. Selectors holds
+// optional tag selectors, such as "env=prod,region=eu-west-1", that help
+// match a local cluster automatically.
 type ClusterMapping struct {
-	Mapping map[string]string `yaml:"mapping"`
+	Mapping   map[string]string `yaml:"mapping"`
+	Selectors map[string]string `yaml:"selectors,omitempty"`
+}
+
+func clusterMappingPath() (string, error) {
+	dir, err := appDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "clusters.yaml"), nil
 }
 
 func loadClusterMapping() (*ClusterMapping, error) {
-	homeDir, err := os.UserHomeDir()
+	clusterMappingFile, err := clusterMappingPath()
 	if err != nil {
 		return &ClusterMapping{Mapping: make(map[string]string)}, nil
 	}
 
-	clusterMappingFile := homeDir + "/.k9s-komodor-rca/clusters.yaml"
-
 	data, err := os.ReadFile(clusterMappingFile)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -43,117 +55,148 @@ func loadClusterMapping() (*ClusterMapping, error) {
 }
 
 func saveClusterMapping(mapping *ClusterMapping) error {
-	homeDir, err := os.UserHomeDir()
+	clusterMappingFile, err := clusterMappingPath()
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+		return err
 	}
-
-	configDir := homeDir + "/.k9s-komodor-rca"
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(clusterMappingFile), 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	clusterMappingFile := configDir + "/clusters.yaml"
 	data, err := yaml.Marshal(mapping)
 	if err != nil {
 		return fmt.Errorf("failed to marshal cluster mapping: %w", err)
 	}
 
-	if err := os.WriteFile(clusterMappingFile, data, 0644); err != nil {
+	if err := writeFileAtomic(clusterMappingFile, data, 0644); err != nil {
 		return fmt.Errorf("failed to write cluster mapping file: %w", err)
 	}
 
 	return nil
 }
 
-func getLocalClusterUID() (string, error) {
-	cmd := exec.Command("kubectl", "get", "namespace", "default", "-o", "json")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("failed to get cluster UID: %w", err)
-	}
-
-	var namespaceData map[string]interface{}
-	if err := json.Unmarshal(output, &namespaceData); err != nil {
-		return "", fmt.Errorf("failed to parse kubectl output: %w", err)
-	}
-
-	metadata, ok := namespaceData["metadata"].(map[string]interface{})
-	if !ok {
-		return "", fmt.Errorf("invalid namespace metadata structure")
-	}
-
-	uid, ok := metadata["uid"].(string)
-	if !ok {
-		return "", fmt.Errorf("cluster UID not found in namespace metadata")
-	}
+// unresolvedClusterError is returned when no single Komodor cluster could
+// be matched to a local cluster. It carries what a user needs to pick one.
+type unresolvedClusterError struct {
+	LocalCluster string
+	Clusters     []komodor.Cluster
+	Candidates   []clusterCandidate
+	message      string
+}
 
-	return uid, nil
+func (e *unresolvedClusterError) Error() string {
+	return e.message
 }
 
-func resolveKomodorCluster(apiKey, baseURL, localClusterName string) (string, error) {
+// resolveKomodorCluster finds the Komodor cluster for config.LocalClusterName,
+// first from the saved mapping and then with matchKomodorCluster against
+// config.Context, or the context found for the cluster if empty. A saved mapping
+// is checked against the cached cluster list, which is only refreshed when the
+// mapped cluster is missing from it. A mapping that is still missing is only
+// replaced by a single high-confidence match; otherwise an
+// unresolvedClusterError is returned so the user can pick the cluster.
+func resolveKomodorCluster(ctx context.Context, config *Config) (string, error) {
+	localClusterName, kubeContext, selector := config.LocalClusterName, config.Context, config.ClusterSelector
+
 	mapping, err := loadClusterMapping()
 	if err != nil {
 		mapping = &ClusterMapping{Mapping: make(map[string]string)}
 	}
+	if selector == "" {
+		selector = mapping.Selectors[localClusterName]
+	}
 
+	var komodorClusters []komodor.Cluster
+	var listErr error
+	staleCluster := ""
 	if komodorCluster, exists := mapping.Mapping[localClusterName]; exists {
-		logMessage("Using mapped Komodor cluster '%s' for local cluster '%s'", komodorCluster, localClusterName)
-		return komodorCluster, nil
+		if len(findClustersByName(komodorCluster, cachedKomodorClusters(config))) > 0 {
+			logMessage("Using mapped Komodor cluster '%s' for local cluster '%s'", komodorCluster, localClusterName)
+			return komodorCluster, nil
+		}
+
+		// The cluster may have been added or renamed since the list was cached.
+		komodorClusters, _, listErr = loadKomodorClusters(ctx, config, true)
+		if listErr != nil {
+			logMessage("⚠️  Could not check mapped Komodor cluster '%s': %v", komodorCluster, listErr)
+			fmt.Fprintf(os.Stderr, "⚠️  Could not check that mapped Komodor cluster %q exists, using it anyway: %v\n", komodorCluster, listErr)
+			return komodorCluster, nil
+		}
+
+		if len(findClustersByName(komodorCluster, komodorClusters)) > 0 {
+			logMessage("Using mapped Komodor cluster '%s' for local cluster '%s'", komodorCluster, localClusterName)
+			return komodorCluster, nil
+		}
+
+		logMessage("⚠️  Mapped Komodor cluster '%s' for local cluster '%s' is not in the Komodor cluster list, matching again", komodorCluster, localClusterName)
+		staleCluster = komodorCluster
+	} else {
+		logMessage("No mapping found for cluster '%s', attempting to match it", localClusterName)
+		komodorClusters, _, listErr = loadKomodorClusters(ctx, config, false)
 	}
 
-	logMessage("No mapping found for cluster '%s', attempting to fetch Komodor clusters", localClusterName)
-	komodorClusters, err := fetchKomodorClusters(apiKey, baseURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch Komodor clusters: %w", err)
+	if listErr != nil {
+		logMessage("ERROR: Failed to fetch Komodor clusters: %v", listErr)
+		return "", fmt.Errorf("failed to fetch Komodor clusters: %w", listErr)
 	}
 
-	matchingCluster := findMatchingClusterByName(localClusterName, komodorClusters)
-	if matchingCluster == nil {
-		logMessage("⚠️  No name match found, trying to match by cluster UID")
-		localClusterUID, err := getLocalClusterUID()
-		if err == nil {
-			matchingCluster = findMatchingClusterByUID(localClusterUID, komodorClusters)
-		} else {
-			logMessage("⚠️  Could not get local cluster UID: %v", err)
+	tags, err := parseTagSelector(selector)
+	if err != nil {
+		return "", err
+	}
+
+	resolution := matchKomodorCluster(ctx, localClusterName, kubeContext, tags, komodorClusters)
+	if resolution.Ambiguous() {
+		logMessage("ERROR: Cluster '%s' matches several Komodor clusters: %s", localClusterName, resolution.CandidateNames())
+		return "", &unresolvedClusterError{
+			LocalCluster: localClusterName,
+			Clusters:     komodorClusters,
+			Candidates:   resolution.Candidates,
+			message: staleNote(staleCluster) + fmt.Sprintf("cluster '%s' matches several Komodor clusters: %s\n\n💡 Pick one with: k9s-rca clusters map \"%s\" <komodor-cluster>",
+				localClusterName, resolution.CandidateNames(), localClusterName),
 		}
 	}
 
-	if matchingCluster != nil {
-		logMessage("✅ Found matching Komodor cluster: '%s'", matchingCluster.Name)
-		mapping.Mapping[localClusterName] = matchingCluster.Name
-		if err := saveClusterMapping(mapping); err != nil {
-			logMessage("⚠️  Could not save cluster mapping: %v", err)
-		} else {
-			logMessage("💾 Saved mapping: '%s' -> '%s'", localClusterName, matchingCluster.Name)
-		}
-		return matchingCluster.Name, nil
+	switch {
+	case resolution.Match != nil && resolution.Confidence >= confidenceHigh:
+		logMessage("✅ Found matching Komodor cluster by %s (%s confidence): '%s'", resolution.By, resolution.Confidence, resolution.Match.Name)
+		saveClusterMatch(mapping, localClusterName, resolution.Match.Name)
+		return resolution.Match.Name, nil
+	case resolution.Match != nil && staleCluster == "":
+		// A tag match is good enough for this run but not to remember.
+		logMessage("✅ Found matching Komodor cluster by %s (%s confidence): '%s'", resolution.By, resolution.Confidence, resolution.Match.Name)
+		return resolution.Match.Name, nil
 	}
 
 	logMessage("ERROR: No matching Komodor cluster found for '%s'", localClusterName)
-	return "", fmt.Errorf("no matching Komodor cluster found for '%s'. Available clusters: %s\n\n💡 To fix this, add a manual mapping to ~/.k9s-komodor-rca/clusters.yaml:\nmapping:\n  \"%s\": \"your-komodor-cluster-name\"",
-		localClusterName, getClusterNames(komodorClusters), localClusterName)
+	return "", &unresolvedClusterError{
+		LocalCluster: localClusterName,
+		Clusters:     komodorClusters,
+		Candidates:   resolution.Candidates,
+		message: staleNote(staleCluster) + fmt.Sprintf("no matching Komodor cluster found for '%s'. Available clusters: %s\n\n💡 To fix this, map it manually: k9s-rca clusters map \"%s\" <komodor-cluster>",
+			localClusterName, getClusterNames(komodorClusters), localClusterName),
+	}
 }
 
-func findMatchingClusterByName(k9sClusterName string, komodorClusters []KomodorCluster) *KomodorCluster {
-	for _, cluster := range komodorClusters {
-		if cluster.Name == k9sClusterName {
-			return &cluster
-		}
+// staleNote explains why a cluster that used to be mapped needs resolving.
+func staleNote(staleCluster string) string {
+	if staleCluster == "" {
+		return ""
 	}
-	return nil
+	return fmt.Sprintf("mapped Komodor cluster '%s' is not in the Komodor cluster list; ", staleCluster)
 }
 
-func findMatchingClusterByUID(localClusterUID string, komodorClusters []KomodorCluster) *KomodorCluster {
-	for _, cluster := range komodorClusters {
-		if cluster.ClusterID == localClusterUID {
-			return &cluster
-		}
+// saveClusterMatch remembers a high-confidence match so later runs skip matching.
+func saveClusterMatch(mapping *ClusterMapping, localClusterName, komodorCluster string) {
+	mapping.Mapping[localClusterName] = komodorCluster
+	if err := saveClusterMapping(mapping); err != nil {
+		logMessage("⚠️  Could not save cluster mapping: %v", err)
+		return
 	}
-	return nil
+	logMessage("💾 Saved mapping: '%s' -> '%s'", localClusterName, komodorCluster)
 }
 
-func getClusterNames(komodorClusters []KomodorCluster) string {
+func getClusterNames(komodorClusters []komodor.Cluster) string {
 	names := make([]string, len(komodorClusters))
 	for i, cluster := range komodorClusters {
 		names[i] = cluster.Name
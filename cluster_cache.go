@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k9s-rca/komodor"
+)
+
+// clusterCache is the last Komodor cluster list fetched, so that resolving
+// a cluster does not cost a request on every run.
+type clusterCache struct {
+	Account   string            `json:"account"`
+	FetchedAt time.Time         `json:"fetchedAt"`
+	Clusters  []komodor.Cluster `json:"clusters"`
+}
+
+func clusterCachePath() (string, error) {
+	dir, err := appDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "clusters_cache.json"), nil
+}
+
+// cacheAccount tells apart cluster lists of different API endpoints and
+// keys without writing the key to disk.
+func cacheAccount(config *Config) string {
+	sum := sha256.Sum256([]byte(config.KomodorAPIKey))
+	return config.KomodorBaseURL + "#" + hex.EncodeToString(sum[:6])
+}
+
+func loadClusterCache() (*clusterCache, error) {
+	path, err := clusterCachePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cache clusterCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("failed to parse cluster cache: %w", err)
+	}
+	return &cache, nil
+}
+
+func saveClusterCache(cache *clusterCache) error {
+	path, err := clusterCachePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cluster cache: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cluster cache: %w", err)
+	}
+	return nil
+}
+
+// cachedKomodorClusters returns the cached cluster list of the account
+// whatever its age, or nil without a cache or when caching is disabled. It
+// is enough to confirm that a mapped cluster exists without a request.
+func cachedKomodorClusters(config *Config) []komodor.Cluster {
+	if config.ClusterCacheTTL <= 0 {
+		return nil
+	}
+	cache, err := loadClusterCache()
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logMessage("⚠️  Could not read cluster cache: %v", err)
+		}
+		return nil
+	}
+	if cache.Account != cacheAccount(config) {
+		return nil
+	}
+	return cache.Clusters
+}
+
+// loadKomodorClusters returns the Komodor cluster list, from the cache when
+// it is younger than config.ClusterCacheTTL and refresh is not set. cached
+// reports whether the list came from the cache.
+func loadKomodorClusters(ctx context.Context, config *Config, refresh bool) (clusters []komodor.Cluster, cached bool, err error) {
+	account := cacheAccount(config)
+	if !refresh && config.ClusterCacheTTL > 0 {
+		cache, err := loadClusterCache()
+		switch {
+		case err == nil && cache.Account == account && time.Since(cache.FetchedAt) < config.ClusterCacheTTL:
+			logMessage("Using %d cached Komodor clusters (fetched %s)", len(cache.Clusters), formatAge(cache.FetchedAt))
+			return cache.Clusters, true, nil
+		case err != nil && !os.IsNotExist(err):
+			logMessage("⚠️  Could not read cluster cache: %v", err)
+		}
+	}
+
+	logMessage("Fetching Komodor clusters from API...")
+	clusters, err = config.Client.ListClusters(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	logMessage("Successfully fetched %d clusters from Komodor API", len(clusters))
+
+	cache := &clusterCache{Account: account, FetchedAt: time.Now(), Clusters: clusters}
+	if err := saveClusterCache(cache); err != nil {
+		logMessage("⚠️  Could not save cluster cache: %v", err)
+	}
+	return clusters, false, nil
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"k9s-rca/komodor"
+)
+
+// matchConfidence is how much a matching strategy is trusted. A cluster UID
+// identifies a cluster exactly; names and API server URLs almost always
+// do; tag selectors narrow things down but often fit several clusters.
+type matchConfidence int
+
+const (
+	confidenceMedium matchConfidence = iota + 1
+	confidenceHigh
+	confidenceExact
+)
+
+func (c matchConfidence) String() string {
+	switch c {
+	case confidenceExact:
+		return "exact"
+	case confidenceHigh:
+		return "high"
+	case confidenceMedium:
+		return "medium"
+	default:
+		return "none"
+	}
+}
+
+// clusterCandidate is a Komodor cluster that fits a local cluster, with the
+// strategies that found it.
+type clusterCandidate struct {
+	Cluster    komodor.Cluster
+	By         []string
+	Confidence matchConfidence
+}
+
+// clusterResolution is the outcome of matching one local cluster against
+// the Komodor cluster list. Candidates holds every cluster found at the
+// highest confidence reached; only a single candidate is a Match.
+type clusterResolution struct {
+	Match      *komodor.Cluster
+	Candidates []clusterCandidate
+	By         string
+	Confidence matchConfidence
+}
+
+func (r clusterResolution) Ambiguous() bool {
+	return len(r.Candidates) > 1
+}
+
+// CandidateNames lists the candidates with what matched them, e.g.
+// "prod-eu (name), prod-us (API server)".
+func (r clusterResolution) CandidateNames() string {
+	names := make([]string, len(r.Candidates))
+	for i, candidate := range r.Candidates {
+		names[i] = fmt.Sprintf("%s (%s)", candidate.Cluster.Name, strings.Join(candidate.By, ", "))
+	}
+	return strings.Join(names, ", ")
+}
+
+// clusterMatcher collects candidates from each strategy, merging the ones
+// that point at the same Komodor cluster.
+type clusterMatcher struct {
+	candidates []clusterCandidate
+}
+
+func (m *clusterMatcher) add(by string, confidence matchConfidence, clusters []komodor.Cluster) {
+	for _, cluster := range clusters {
+		found := false
+		for i := range m.candidates {
+			candidate := &m.candidates[i]
+			if sameCluster(candidate.Cluster, cluster) {
+				candidate.By = append(candidate.By, by)
+				candidate.Confidence = max(candidate.Confidence, confidence)
+				found = true
+				break
+			}
+		}
+		if !found {
+			m.candidates = append(m.candidates, clusterCandidate{Cluster: cluster, By: []string{by}, Confidence: confidence})
+		}
+	}
+}
+
+func (m *clusterMatcher) resolve() clusterResolution {
+	var resolution clusterResolution
+	for _, candidate := range m.candidates {
+		switch {
+		case candidate.Confidence > resolution.Confidence:
+			resolution.Confidence = candidate.Confidence
+			resolution.Candidates = []clusterCandidate{candidate}
+		case candidate.Confidence == resolution.Confidence:
+			resolution.Candidates = append(resolution.Candidates, candidate)
+		}
+	}
+	if len(resolution.Candidates) == 1 {
+		resolution.Match = &resolution.Candidates[0].Cluster
+		resolution.By = strings.Join(resolution.Candidates[0].By, " and ")
+	}
+	return resolution
+}
+
+func sameCluster(a, b komodor.Cluster) bool {
+	if a.ClusterID != "" || b.ClusterID != "" {
+		return a.ClusterID == b.ClusterID
+	}
+	return a.Name == b.Name
+}
+
+// matchKomodorCluster matches a local cluster by name, by the API server of
+// kubeContext and by the tag selector. The kube-system UID of kubeContext,
+// which needs a round trip to the cluster, is only checked when those
+// leave no single high-confidence match.
+//
+// Without a kubeContext, the context for localClusterName is looked up in
+// the kubeconfig. The current context is never assumed: it may point at a
+// different cluster, so without a context only name and tags are used.
+func matchKomodorCluster(ctx context.Context, localClusterName, kubeContext string, selector tagSelector, komodorClusters []komodor.Cluster) clusterResolution {
+	var matcher clusterMatcher
+	matcher.add("name", confidenceHigh, findClustersByName(localClusterName, komodorClusters))
+
+	if kubeContext == "" {
+		kubeContext = kubeContextForCluster(localClusterName)
+	}
+	if kubeContext == "" {
+		logMessage("⚠️  No kubeconfig context for '%s', matching by name and tags only", localClusterName)
+		if len(selector) > 0 {
+			matcher.add("tags "+selector.String(), confidenceMedium, findClustersByTags(selector, komodorClusters))
+		}
+		return matcher.resolve()
+	}
+
+	if server, err := kubeServerURL(kubeContext); err == nil {
+		matcher.add("API server", confidenceHigh, findClustersByServer(server, komodorClusters))
+	} else {
+		logMessage("⚠️  Could not read the API server of context '%s': %v", kubeContext, err)
+	}
+
+	if len(selector) > 0 {
+		matcher.add("tags "+selector.String(), confidenceMedium, findClustersByTags(selector, komodorClusters))
+	}
+
+	if resolution := matcher.resolve(); resolution.Match != nil && resolution.Confidence >= confidenceHigh {
+		return resolution
+	}
+
+	logMessage("⚠️  No conclusive match for '%s', trying to match by cluster UID", localClusterName)
+	localClusterUID, err := getLocalClusterUID(ctx, kubeContext)
+	if err != nil {
+		logMessage("⚠️  Could not get local cluster UID: %v", err)
+	} else {
+		matcher.add("UID", confidenceExact, findClustersByUID(localClusterUID, komodorClusters))
+	}
+	return matcher.resolve()
+}
+
+func findClustersByName(k9sClusterName string, komodorClusters []komodor.Cluster) []komodor.Cluster {
+	var matches []komodor.Cluster
+	for _, cluster := range komodorClusters {
+		if cluster.Name == k9sClusterName {
+			matches = append(matches, cluster)
+		}
+	}
+	return matches
+}
+
+func findClustersByUID(localClusterUID string, komodorClusters []komodor.Cluster) []komodor.Cluster {
+	var matches []komodor.Cluster
+	for _, cluster := range komodorClusters {
+		if cluster.ClusterID == localClusterUID {
+			matches = append(matches, cluster)
+		}
+	}
+	return matches
+}
+
+func findClustersByServer(server string, komodorClusters []komodor.Cluster) []komodor.Cluster {
+	want, ok := normalizeServerURL(server)
+	if !ok {
+		return nil
+	}
+
+	var matches []komodor.Cluster
+	for _, cluster := range komodorClusters {
+		if got, ok := normalizeServerURL(cluster.APIServerURL); ok && got == want {
+			matches = append(matches, cluster)
+		}
+	}
+	return matches
+}
+
+// normalizeServerURL reduces an API server URL to scheme://host:port, so
+// that "https://API.example.com/" and "api.example.com:443" compare equal.
+func normalizeServerURL(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", false
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return "", false
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if scheme == "http" {
+			port = "80"
+		}
+	}
+	return scheme + "://" + strings.ToLower(u.Hostname()) + ":" + port, true
+}
+
+func findClustersByTags(selector tagSelector, komodorClusters []komodor.Cluster) []komodor.Cluster {
+	var matches []komodor.Cluster
+	for _, cluster := range komodorClusters {
+		if selector.Matches(cluster.Tags) {
+			matches = append(matches, cluster)
+		}
+	}
+	return matches
+}
+
+// tagSelector requires every key to be present with the given value.
+type tagSelector map[string]string
+
+// parseTagSelector parses "key=value,key=value". An empty string is an
+// empty selector.
+func parseTagSelector(s string) (tagSelector, error) {
+	selector := tagSelector{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid cluster selector %q: expected key=value pairs separated by commas", s)
+		}
+		selector[key] = strings.TrimSpace(value)
+	}
+	return selector, nil
+}
+
+func (s tagSelector) Matches(tags map[string]string) bool {
+	for key, value := range s {
+		if got, ok := tags[key]; !ok || got != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (s tagSelector) String() string {
+	return formatTags(s)
+}
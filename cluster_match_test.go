@@ -0,0 +1,186 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"k9s-rca/komodor"
+)
+
+func TestNormalizeServerURL(t *testing.T) {
+	tests := []struct {
+		raw    string
+		want   string
+		wantOK bool
+	}{
+		{"https://api.example.com", "https://api.example.com:443", true},
+		{"https://API.Example.com/", "https://api.example.com:443", true},
+		{"https://api.example.com:443/k8s/clusters/c-1", "https://api.example.com:443", true},
+		{"api.example.com:6443", "https://api.example.com:6443", true},
+		{"http://10.0.0.1", "http://10.0.0.1:80", true},
+		{"  https://10.0.0.1:6443  ", "https://10.0.0.1:6443", true},
+		{"", "", false},
+		{"https://", "", false},
+		{"://bad", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, ok := normalizeServerURL(tt.raw)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("normalizeServerURL(%q) = %q, %t, want %q, %t", tt.raw, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseTagSelector(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    tagSelector
+		wantErr bool
+	}{
+		{"", tagSelector{}, false},
+		{"env=prod", tagSelector{"env": "prod"}, false},
+		{" env = prod , region=eu-west-1 ,", tagSelector{"env": "prod", "region": "eu-west-1"}, false},
+		{"team=", tagSelector{"team": ""}, false},
+		{"env", nil, true},
+		{"=prod", nil, true},
+		{"env=prod,region", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseTagSelector(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTagSelector(%q) error = %v, wantErr %t", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTagSelector(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagSelectorMatches(t *testing.T) {
+	tags := map[string]string{"env": "prod", "region": "eu-west-1"}
+
+	tests := []struct {
+		selector tagSelector
+		want     bool
+	}{
+		{tagSelector{}, true},
+		{tagSelector{"env": "prod"}, true},
+		{tagSelector{"env": "prod", "region": "eu-west-1"}, true},
+		{tagSelector{"env": "staging"}, false},
+		{tagSelector{"team": ""}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.selector.Matches(tags); got != tt.want {
+			t.Errorf("%v.Matches(%v) = %t, want %t", tt.selector, tags, got, tt.want)
+		}
+	}
+}
+
+func TestClusterMatcherResolve(t *testing.T) {
+	prodEU := komodor.Cluster{Name: "prod-eu", ClusterID: "uid-eu"}
+	prodUS := komodor.Cluster{Name: "prod-us", ClusterID: "uid-us"}
+
+	type match struct {
+		by         string
+		confidence matchConfidence
+		clusters   []komodor.Cluster
+	}
+	tests := []struct {
+		name           string
+		matches        []match
+		wantMatch      string
+		wantBy         string
+		wantConfidence matchConfidence
+		wantCandidates int
+	}{
+		{
+			name: "nothing matches",
+		},
+		{
+			name:           "single high match",
+			matches:        []match{{"name", confidenceHigh, []komodor.Cluster{prodEU}}},
+			wantMatch:      "prod-eu",
+			wantBy:         "name",
+			wantConfidence: confidenceHigh,
+			wantCandidates: 1,
+		},
+		{
+			name: "strategies agreeing are merged",
+			matches: []match{
+				{"name", confidenceHigh, []komodor.Cluster{prodEU}},
+				{"API server", confidenceHigh, []komodor.Cluster{prodEU}},
+			},
+			wantMatch:      "prod-eu",
+			wantBy:         "name and API server",
+			wantConfidence: confidenceHigh,
+			wantCandidates: 1,
+		},
+		{
+			name: "high beats medium",
+			matches: []match{
+				{"tags env=prod", confidenceMedium, []komodor.Cluster{prodEU, prodUS}},
+				{"API server", confidenceHigh, []komodor.Cluster{prodUS}},
+			},
+			wantMatch:      "prod-us",
+			wantBy:         "tags env=prod and API server",
+			wantConfidence: confidenceHigh,
+			wantCandidates: 1,
+		},
+		{
+			name: "disagreeing high matches are ambiguous",
+			matches: []match{
+				{"name", confidenceHigh, []komodor.Cluster{prodEU}},
+				{"API server", confidenceHigh, []komodor.Cluster{prodUS}},
+			},
+			wantConfidence: confidenceHigh,
+			wantCandidates: 2,
+		},
+		{
+			name: "exact settles an ambiguity",
+			matches: []match{
+				{"name", confidenceHigh, []komodor.Cluster{prodEU}},
+				{"API server", confidenceHigh, []komodor.Cluster{prodUS}},
+				{"UID", confidenceExact, []komodor.Cluster{prodUS}},
+			},
+			wantMatch:      "prod-us",
+			wantBy:         "API server and UID",
+			wantConfidence: confidenceExact,
+			wantCandidates: 1,
+		},
+		{
+			name:           "several tag matches are ambiguous",
+			matches:        []match{{"tags env=prod", confidenceMedium, []komodor.Cluster{prodEU, prodUS}}},
+			wantConfidence: confidenceMedium,
+			wantCandidates: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var matcher clusterMatcher
+			for _, m := range tt.matches {
+				matcher.add(m.by, m.confidence, m.clusters)
+			}
+			got := matcher.resolve()
+
+			gotMatch := ""
+			if got.Match != nil {
+				gotMatch = got.Match.Name
+			}
+			if gotMatch != tt.wantMatch || got.By != tt.wantBy || got.Confidence != tt.wantConfidence || len(got.Candidates) != tt.wantCandidates {
+				t.Errorf("resolve() = match %q by %q (%s, %d candidates), want %q by %q (%s, %d candidates)",
+					gotMatch, got.By, got.Confidence, len(got.Candidates), tt.wantMatch, tt.wantBy, tt.wantConfidence, tt.wantCandidates)
+			}
+			if got.Ambiguous() != (tt.wantCandidates > 1) {
+				t.Errorf("Ambiguous() = %t with %d candidates", got.Ambiguous(), len(got.Candidates))
+			}
+		})
+	}
+}
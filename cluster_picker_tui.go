@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+
+	"k9s-rca/komodor"
+)
+
+var errNoClusterPicked = errors.New("no Komodor cluster selected")
+
+type clusterItem struct {
+	cluster   komodor.Cluster
+	candidate string
+}
+
+func (i clusterItem) Title() string {
+	if i.candidate != "" {
+		return fmt.Sprintf("%s  (matches by %s)", i.cluster.Name, i.candidate)
+	}
+	return i.cluster.Name
+}
+
+func (i clusterItem) Description() string {
+	var parts []string
+	for _, part := range []string{i.cluster.ClusterID, i.cluster.APIServerURL, formatTags(i.cluster.Tags)} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " • ")
+}
+
+func (i clusterItem) FilterValue() string {
+	return strings.Join([]string{
+		i.cluster.Name,
+		i.cluster.ClusterID,
+		i.cluster.APIServerURL,
+		formatTags(i.cluster.Tags),
+	}, " ")
+}
+
+type clusterPickerModel struct {
+	list        list.Model
+	selected    *komodor.Cluster
+	interrupted bool
+}
+
+// newClusterPickerModel lists the candidates of an ambiguous match first,
+// then every other cluster by name.
+func newClusterPickerModel(localCluster string, clusters []komodor.Cluster, candidates []clusterCandidate) clusterPickerModel {
+	items := make([]list.Item, 0, len(clusters))
+	for _, candidate := range candidates {
+		items = append(items, clusterItem{cluster: candidate.Cluster, candidate: strings.Join(candidate.By, ", ")})
+	}
+
+	others := make([]komodor.Cluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		isCandidate := false
+		for _, candidate := range candidates {
+			if sameCluster(candidate.Cluster, cluster) {
+				isCandidate = true
+				break
+			}
+		}
+		if !isCandidate {
+			others = append(others, cluster)
+		}
+	}
+	sort.Slice(others, func(i, j int) bool {
+		return others[i].Name < others[j].Name
+	})
+	for _, cluster := range others {
+		items = append(items, clusterItem{cluster: cluster})
+	}
+
+	l := list.New(items, list.NewDefaultDelegate(), 0, 0)
+	l.Title = fmt.Sprintf("Komodor cluster for %s", localCluster)
+	l.Styles.Title = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("86")).
+		Background(lipgloss.Color("235")).
+		Padding(0, 1)
+	l.SetStatusBarItemName("cluster", "clusters")
+
+	return clusterPickerModel{list: l}
+}
+
+func (m clusterPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m clusterPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetSize(msg.Width, msg.Height)
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		switch msg.String() {
+		case "ctrl+c":
+			m.interrupted = true
+			return m, tea.Quit
+		case "q":
+			return m, tea.Quit
+		case "enter":
+			if item, ok := m.list.SelectedItem().(clusterItem); ok {
+				m.selected = &item.cluster
+				return m, tea.Quit
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m clusterPickerModel) View() string {
+	return m.list.View()
+}
+
+// canPickCluster reports whether the user can be asked to pick a cluster:
+// only in the interactive TUI, with a terminal on both ends.
+func canPickCluster(config *Config) bool {
+	if _, ok := config.TUI.(*BubbleTeaTUI); !ok {
+		return false
+	}
+	return term.IsTerminal(os.Stdin.Fd()) && term.IsTerminal(os.Stdout.Fd())
+}
+
+// pickKomodorCluster lets the user choose the Komodor cluster that automatic
+// resolution could not, and saves the choice as the cluster's mapping.
+func pickKomodorCluster(ctx context.Context, unresolved *unresolvedClusterError) (string, error) {
+	model := newClusterPickerModel(unresolved.LocalCluster, unresolved.Clusters, unresolved.Candidates)
+
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithContext(ctx))
+	final, err := p.Run()
+	if cause := context.Cause(ctx); cause != nil {
+		return "", cause
+	}
+	if err != nil {
+		if errors.Is(err, tea.ErrInterrupted) {
+			return "", errInterrupted
+		}
+		return "", fmt.Errorf("error running cluster picker: %w", err)
+	}
+
+	finalModel := final.(clusterPickerModel)
+	if finalModel.interrupted {
+		return "", errInterrupted
+	}
+	selected := finalModel.selected
+	if selected == nil {
+		return "", fmt.Errorf("%w for '%s'", errNoClusterPicked, unresolved.LocalCluster)
+	}
+
+	logMessage("👆 Picked Komodor cluster '%s' for local cluster '%s'", selected.Name, unresolved.LocalCluster)
+	mapping, err := loadClusterMapping()
+	if err != nil {
+		mapping = &ClusterMapping{Mapping: make(map[string]string)}
+	}
+	mapping.Mapping[unresolved.LocalCluster] = selected.Name
+	if err := saveClusterMapping(mapping); err != nil {
+		logMessage("⚠️  Could not save cluster mapping: %v", err)
+	} else {
+		logMessage("💾 Saved mapping: '%s' -> '%s'", unresolved.LocalCluster, selected.Name)
+	}
+	return selected.Name, nil
+}
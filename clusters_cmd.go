@@ -0,0 +1,347 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"k9s-rca/komodor"
+)
+
+func newClustersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clusters",
+		Short: "Inspect Komodor clusters and manage the local cluster mapping",
+		Long: "Local clusters are matched to Komodor clusters automatically and the result is " +
+			"saved to ~/.k9s-komodor-rca/clusters.yaml. These commands show and fix that mapping.",
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "List the Komodor clusters visible to the API key",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				ctx, cancel := commandContext(cmd)
+				defer cancel()
+
+				return withContextCause(ctx, listClusters(ctx, cmd))
+			},
+		},
+		&cobra.Command{
+			Use:   "show [local-cluster]",
+			Short: "Show the saved cluster mapping",
+			Args:  cobra.MaximumNArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return showClusterMapping(os.Stdout, args)
+			},
+		},
+		&cobra.Command{
+			Use:   "map <local-cluster> <komodor-cluster>",
+			Short: "Map a local cluster or context to a Komodor cluster",
+			Args:  cobra.ExactArgs(2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				ctx, cancel := commandContext(cmd)
+				defer cancel()
+
+				return withContextCause(ctx, mapCluster(ctx, cmd, args[0], args[1]))
+			},
+		},
+		&cobra.Command{
+			Use:   "unmap <local-cluster>",
+			Short: "Remove a saved mapping so the cluster is matched automatically again",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return unmapCluster(args[0])
+			},
+		},
+		&cobra.Command{
+			Use:   "select <local-cluster> <selector>",
+			Short: "Save a tag selector, e.g. env=prod,region=eu-west-1, used to match a local cluster",
+			Long: "Komodor clusters whose tags contain every key=value pair of the selector are " +
+				"candidates for the local cluster. Tags are a weaker signal than the cluster name, " +
+				"API server or UID, so they only decide when those find nothing. An empty selector " +
+				"removes the saved one.",
+			Args: cobra.ExactArgs(2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return selectCluster(args[0], args[1])
+			},
+		},
+		newClustersAutoCmd(),
+	)
+	return cmd
+}
+
+func newClustersAutoCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auto",
+		Short: "Match every kubeconfig context to a Komodor cluster",
+		Long: "Run the automatic matching for every context in the kubeconfig, save the " +
+			"contexts that match exactly one Komodor cluster by name, API server or UID and " +
+			"report the ones that are ambiguous, only match by tags or have no match. " +
+			"Existing mappings are kept.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+
+			return withContextCause(ctx, autoMapClusters(ctx, cmd))
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "Report the matches without saving them")
+	return cmd
+}
+
+// loadClusters fetches the Komodor cluster list for the clusters commands,
+// bypassing and refreshing the cache.
+func loadClusters(ctx context.Context, cmd *cobra.Command) ([]komodor.Cluster, error) {
+	config := loadAPIConfig(cmd, NewBubbleTeaTUI())
+	if err := validateAPIConfig(config); err != nil {
+		return nil, err
+	}
+
+	clusters, _, err := loadKomodorClusters(ctx, config, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Komodor clusters: %w", err)
+	}
+	return clusters, nil
+}
+
+func listClusters(ctx context.Context, cmd *cobra.Command) error {
+	clusters, err := loadClusters(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].Name < clusters[j].Name
+	})
+	return writeClusterTable(os.Stdout, clusters)
+}
+
+func writeClusterTable(w io.Writer, clusters []komodor.Cluster) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tID\tAPI SERVER\tTAGS")
+	for _, cluster := range clusters {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", cluster.Name, cluster.ClusterID, cluster.APIServerURL, formatTags(cluster.Tags))
+	}
+	return tw.Flush()
+}
+
+// formatTags renders tags as sorted key=value pairs.
+func formatTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for key, value := range tags {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func showClusterMapping(w io.Writer, args []string) error {
+	mapping, err := loadClusterMapping()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		komodorCluster, ok := mapping.Mapping[args[0]]
+		if !ok {
+			if selector := mapping.Selectors[args[0]]; selector != "" {
+				return fmt.Errorf("cluster %q is not mapped; it will be matched automatically with selector %s", args[0], selector)
+			}
+			return fmt.Errorf("cluster %q is not mapped; it will be matched automatically", args[0])
+		}
+		fmt.Fprintln(w, komodorCluster)
+		return nil
+	}
+
+	if len(mapping.Mapping) == 0 && len(mapping.Selectors) == 0 {
+		fmt.Fprintln(w, "No cluster mappings saved yet.")
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var locals []string
+	for _, names := range []map[string]string{mapping.Mapping, mapping.Selectors} {
+		for local := range names {
+			if !seen[local] {
+				seen[local] = true
+				locals = append(locals, local)
+			}
+		}
+	}
+	sort.Strings(locals)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LOCAL CLUSTER\tKOMODOR CLUSTER\tSELECTOR")
+	for _, local := range locals {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", local, cmp.Or(mapping.Mapping[local], "-"), cmp.Or(mapping.Selectors[local], "-"))
+	}
+	return tw.Flush()
+}
+
+func mapCluster(ctx context.Context, cmd *cobra.Command, localCluster, komodorCluster string) error {
+	clusters, err := loadClusters(ctx, cmd)
+	switch {
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "⚠️  Could not check that %q exists in Komodor: %v\n", komodorCluster, err)
+	case len(findClustersByName(komodorCluster, clusters)) == 0:
+		return fmt.Errorf("no Komodor cluster named %q (see k9s-rca clusters list)", komodorCluster)
+	}
+
+	mapping, err := loadClusterMapping()
+	if err != nil {
+		return err
+	}
+	mapping.Mapping[localCluster] = komodorCluster
+	if err := saveClusterMapping(mapping); err != nil {
+		return err
+	}
+
+	fmt.Printf("💾 Mapped %s -> %s\n", localCluster, komodorCluster)
+	return nil
+}
+
+func unmapCluster(localCluster string) error {
+	mapping, err := loadClusterMapping()
+	if err != nil {
+		return err
+	}
+	if _, ok := mapping.Mapping[localCluster]; !ok {
+		return fmt.Errorf("cluster %q is not mapped", localCluster)
+	}
+
+	delete(mapping.Mapping, localCluster)
+	if err := saveClusterMapping(mapping); err != nil {
+		return err
+	}
+
+	fmt.Printf("🗑️  Removed the mapping for %s\n", localCluster)
+	return nil
+}
+
+func selectCluster(localCluster, selector string) error {
+	tags, err := parseTagSelector(selector)
+	if err != nil {
+		return err
+	}
+
+	mapping, err := loadClusterMapping()
+	if err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		if _, ok := mapping.Selectors[localCluster]; !ok {
+			return fmt.Errorf("cluster %q has no selector", localCluster)
+		}
+		delete(mapping.Selectors, localCluster)
+		if err := saveClusterMapping(mapping); err != nil {
+			return err
+		}
+		fmt.Printf("🗑️  Removed the selector for %s\n", localCluster)
+		return nil
+	}
+
+	if mapping.Selectors == nil {
+		mapping.Selectors = make(map[string]string)
+	}
+	mapping.Selectors[localCluster] = tags.String()
+	if err := saveClusterMapping(mapping); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Clusters tagged %s are now candidates for %s\n", tags, localCluster)
+	if _, ok := mapping.Mapping[localCluster]; ok {
+		fmt.Printf("ℹ️  %s is mapped already; run k9s-rca clusters unmap \"%s\" for the selector to take effect\n", localCluster, localCluster)
+	}
+	return nil
+}
+
+func autoMapClusters(ctx context.Context, cmd *cobra.Command) error {
+	rawConfig, err := kubeClientConfig("").RawConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	if len(rawConfig.Contexts) == 0 {
+		return fmt.Errorf("no contexts found in kubeconfig")
+	}
+
+	clusters, err := loadClusters(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	mapping, err := loadClusterMapping()
+	if err != nil {
+		return err
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	contexts := make([]string, 0, len(rawConfig.Contexts))
+	for name := range rawConfig.Contexts {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONTEXT\tSTATUS\tKOMODOR CLUSTER\tDETAILS")
+	added := 0
+	for _, kubeContext := range contexts {
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+
+		selector, err := parseTagSelector(mapping.Selectors[kubeContext])
+		if err != nil {
+			return err
+		}
+
+		resolution := matchKomodorCluster(ctx, kubeContext, kubeContext, selector, clusters)
+		saved, isMapped := mapping.Mapping[kubeContext]
+		switch {
+		case isMapped:
+			details := ""
+			if resolution.Match != nil && resolution.Match.Name != saved {
+				details = fmt.Sprintf("automatic match by %s would pick %s", resolution.By, resolution.Match.Name)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", kubeContext, "mapped", saved, details)
+		case resolution.Ambiguous():
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", kubeContext, "ambiguous", "-",
+				fmt.Sprintf("%d clusters match: %s", len(resolution.Candidates), resolution.CandidateNames()))
+		case resolution.Match != nil && resolution.Confidence < confidenceHigh:
+			// Tags alone are not enough to save a mapping for good.
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", kubeContext, "possible", resolution.Match.Name, "by "+resolution.By+", not saved")
+		case resolution.Match != nil:
+			mapping.Mapping[kubeContext] = resolution.Match.Name
+			added++
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", kubeContext, "matched", resolution.Match.Name,
+				fmt.Sprintf("by %s (%s confidence)", resolution.By, resolution.Confidence))
+		default:
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", kubeContext, "no match", "-", "")
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if added == 0 || dryRun {
+		if added > 0 {
+			fmt.Printf("\n%d new %s not saved (--dry-run)\n", added, plural(added, "mapping", "mappings"))
+		}
+		return nil
+	}
+	if err := saveClusterMapping(mapping); err != nil {
+		return err
+	}
+	fmt.Printf("\n💾 Saved %d new %s\n", added, plural(added, "mapping", "mappings"))
+	return nil
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"k9s-rca/komodor"
+)
+
+// compareVerdict sums up how a newer analysis of a resource relates to an
+// older one.
+type compareVerdict string
+
+const (
+	verdictPending   compareVerdict = "pending"
+	verdictFailed    compareVerdict = "failed"
+	verdictResolved  compareVerdict = "resolved"
+	verdictUnchanged compareVerdict = "unchanged"
+	verdictChanged   compareVerdict = "changed"
+)
+
+type rowKind int
+
+const (
+	rowSame rowKind = iota
+	rowChanged
+	rowRemoved
+	rowAdded
+)
+
+// compareRow is one line of the side-by-side view. Removed rows only have
+// a before side and added rows only an after side.
+type compareRow struct {
+	kind   rowKind
+	before string
+	after  string
+}
+
+// rcaComparison lines up two RCA results for the same resource.
+type rcaComparison struct {
+	before *komodor.RCAPollResponse
+	after  *komodor.RCAPollResponse
+
+	verdict        compareVerdict
+	problem        compareRow
+	recommendation compareRow
+	whatHappened   []compareRow
+	evidence       []compareRow
+}
+
+func compareResults(before, after *komodor.RCAPollResponse) rcaComparison {
+	c := rcaComparison{
+		before:         before,
+		after:          after,
+		problem:        compareText(before.ProblemShort, after.ProblemShort),
+		recommendation: compareText(before.Recommendation, after.Recommendation),
+		whatHappened:   compareLists(before.WhatHappened, after.WhatHappened),
+		evidence:       compareEvidence(before.EvidenceCollection, after.EvidenceCollection),
+	}
+
+	switch {
+	case !after.IsComplete && !after.IsFailed:
+		c.verdict = verdictPending
+	case after.IsFailed:
+		// A failed analysis has no problem either; that is not a fix.
+		c.verdict = verdictFailed
+	case after.IsComplete && after.ProblemShort == "" && before.ProblemShort != "":
+		c.verdict = verdictResolved
+	case c.problem.kind == rowSame && c.recommendation.kind == rowSame:
+		c.verdict = verdictUnchanged
+	default:
+		c.verdict = verdictChanged
+	}
+	return c
+}
+
+// Summary is a one-line reading of the verdict.
+func (c rcaComparison) Summary() string {
+	switch c.verdict {
+	case verdictPending:
+		return "⏳ The new analysis is still running"
+	case verdictFailed:
+		return "❌ The new analysis failed, so there is nothing to compare against"
+	case verdictResolved:
+		return "✅ Looks resolved: the new analysis found no problem"
+	case verdictUnchanged:
+		return "⚠️  Unchanged: the new analysis reports the same problem"
+	default:
+		return "🔄 Changed: the new analysis reports something different"
+	}
+}
+
+func normalizeText(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+func compareText(before, after string) compareRow {
+	switch {
+	case normalizeText(before) == normalizeText(after):
+		return compareRow{kind: rowSame, before: before, after: after}
+	case before == "":
+		return compareRow{kind: rowAdded, after: after}
+	case after == "":
+		return compareRow{kind: rowRemoved, before: before}
+	default:
+		return compareRow{kind: rowChanged, before: before, after: after}
+	}
+}
+
+// compareLists aligns two lists on their longest common subsequence, so
+// entries both results share line up and the rest show as removed or added.
+func compareLists(before, after []string) []compareRow {
+	keys := func(items []string) []string {
+		out := make([]string, len(items))
+		for i, item := range items {
+			out[i] = normalizeText(item)
+		}
+		return out
+	}
+
+	var rows []compareRow
+	for _, pair := range alignKeys(keys(before), keys(after)) {
+		switch {
+		case pair.after < 0:
+			rows = append(rows, compareRow{kind: rowRemoved, before: before[pair.before]})
+		case pair.before < 0:
+			rows = append(rows, compareRow{kind: rowAdded, after: after[pair.after]})
+		default:
+			rows = append(rows, compareRow{kind: rowSame, before: before[pair.before], after: after[pair.after]})
+		}
+	}
+	return rows
+}
+
+// alignedPair points at an entry of each list; -1 means the entry exists
+// in the other list only.
+type alignedPair struct {
+	before, after int
+}
+
+// alignKeys lines up a and b on their longest common subsequence.
+func alignKeys(a, b []string) []alignedPair {
+	// lcs[i][j] is the common length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var pairs []alignedPair
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			pairs = append(pairs, alignedPair{i, j})
+			i, j = i+1, j+1
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			pairs = append(pairs, alignedPair{i, -1})
+			i++
+		default:
+			pairs = append(pairs, alignedPair{-1, j})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		pairs = append(pairs, alignedPair{i, -1})
+	}
+	for ; j < len(b); j++ {
+		pairs = append(pairs, alignedPair{-1, j})
+	}
+	return pairs
+}
+
+// compareEvidence diffs evidence as an ordered list keyed by query. A query
+// that both results ran with a different outcome shows as changed; a query
+// run several times is matched occurrence by occurrence.
+func compareEvidence(before, after []komodor.Evidence) []compareRow {
+	keys := func(items []komodor.Evidence) []string {
+		seen := map[string]int{}
+		out := make([]string, len(items))
+		for i, item := range items {
+			query := normalizeText(item.Query)
+			out[i] = fmt.Sprintf("%s\x00%d", query, seen[query])
+			seen[query]++
+		}
+		return out
+	}
+
+	var rows []compareRow
+	for _, pair := range alignKeys(keys(before), keys(after)) {
+		switch {
+		case pair.after < 0:
+			rows = append(rows, compareRow{kind: rowRemoved, before: evidenceSummary(before[pair.before])})
+		case pair.before < 0:
+			rows = append(rows, compareRow{kind: rowAdded, after: evidenceSummary(after[pair.after])})
+		default:
+			b, a := before[pair.before], after[pair.after]
+			row := compareRow{kind: rowSame, before: evidenceSummary(b), after: evidenceSummary(a)}
+			if normalizeText(b.Snippet) != normalizeText(a.Snippet) {
+				row.kind = rowChanged
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// maxEvidenceLines caps how much of a snippet the comparison shows.
+const maxEvidenceLines = 4
+
+func evidenceSummary(e komodor.Evidence) string {
+	lines := strings.Split(strings.TrimSpace(e.Snippet), "\n")
+	if len(lines) > maxEvidenceLines {
+		lines = append(lines[:maxEvidenceLines], fmt.Sprintf("… %d more lines", len(lines)-maxEvidenceLines))
+	}
+	return e.Query + "\n" + strings.Join(lines, "\n")
+}
+
+var (
+	compareRemovedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("203"))
+	compareAddedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("78"))
+	compareChangedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("221"))
+	compareSameStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+)
+
+// renderComparison draws the comparison as two columns, older result on
+// the left. Each line is marked with -, + or ~ so it still reads without
+// colour.
+func renderComparison(c rcaComparison, width int) string {
+	if width <= 0 {
+		width = 120
+	}
+	column := max((width-3)/2, 20)
+
+	var s strings.Builder
+	s.WriteString(sectionStyle.MarginTop(0).Render(c.Summary()))
+	s.WriteString("\n\n")
+	s.WriteString(compareColumns(column,
+		labelStyle.Render("Before: "+c.before.SessionID),
+		labelStyle.Render("After:  "+c.after.SessionID)))
+	s.WriteString("\n")
+
+	section := func(title string, rows []compareRow) {
+		s.WriteString(sectionStyle.Render(title))
+		s.WriteString("\n")
+		if len(rows) == 0 {
+			s.WriteString(itemStyle.Render(labelStyle.Render("(none)")))
+			s.WriteString("\n")
+			return
+		}
+		for _, row := range rows {
+			s.WriteString(renderCompareRow(row, column))
+			s.WriteString("\n")
+		}
+	}
+
+	section("📋 Problem", textRows(c.problem))
+	section("💡 Recommendation", textRows(c.recommendation))
+	section("📝 What Happened", c.whatHappened)
+	section("🔍 Evidence", c.evidence)
+	return strings.TrimSuffix(s.String(), "\n")
+}
+
+// textRows drops a row where neither result has any text.
+func textRows(row compareRow) []compareRow {
+	if row.before == "" && row.after == "" {
+		return nil
+	}
+	return []compareRow{row}
+}
+
+func renderCompareRow(row compareRow, column int) string {
+	style, marker := compareSameStyle, "  "
+	switch row.kind {
+	case rowChanged:
+		style, marker = compareChangedStyle, "~ "
+	case rowRemoved:
+		style, marker = compareRemovedStyle, "- "
+	case rowAdded:
+		style, marker = compareAddedStyle, "+ "
+	}
+
+	side := func(text string) string {
+		if text == "" {
+			return ""
+		}
+		return style.Width(column).Render(marker + text)
+	}
+	return compareColumns(column, side(row.before), side(row.after))
+}
+
+func compareColumns(column int, left, right string) string {
+	cell := lipgloss.NewStyle().Width(column)
+	left, right = cell.Render(left), cell.Render(right)
+	divider := strings.TrimSuffix(strings.Repeat(" │ \n", max(lipgloss.Height(left), lipgloss.Height(right))), "\n")
+	return lipgloss.JoinHorizontal(lipgloss.Top, left, labelStyle.Render(divider), right)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"k9s-rca/komodor"
+)
+
+func TestCompareLists(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after []string
+		want          []compareRow
+	}{
+		{
+			name: "both empty",
+		},
+		{
+			name:   "identical ignoring case and spacing",
+			before: []string{"Pod  restarted", "OOMKilled"},
+			after:  []string{"pod restarted", "oomkilled"},
+			want: []compareRow{
+				{kind: rowSame, before: "Pod  restarted", after: "pod restarted"},
+				{kind: rowSame, before: "OOMKilled", after: "oomkilled"},
+			},
+		},
+		{
+			name:   "added and removed",
+			before: []string{"a", "b", "c"},
+			after:  []string{"a", "c", "d"},
+			want: []compareRow{
+				{kind: rowSame, before: "a", after: "a"},
+				{kind: rowRemoved, before: "b"},
+				{kind: rowSame, before: "c", after: "c"},
+				{kind: rowAdded, after: "d"},
+			},
+		},
+		{
+			name:   "everything new",
+			before: []string{"x"},
+			after:  []string{"y"},
+			want: []compareRow{
+				{kind: rowRemoved, before: "x"},
+				{kind: rowAdded, after: "y"},
+			},
+		},
+		{
+			name:   "duplicates line up in order",
+			before: []string{"retry", "retry"},
+			after:  []string{"retry"},
+			want: []compareRow{
+				{kind: rowSame, before: "retry", after: "retry"},
+				{kind: rowRemoved, before: "retry"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareLists(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("compareLists() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareEvidenceDuplicateQueries(t *testing.T) {
+	before := []komodor.Evidence{{Query: "logs", Snippet: "error 1"}, {Query: "logs", Snippet: "error 2"}}
+	after := []komodor.Evidence{{Query: "logs", Snippet: "error 1"}, {Query: "logs", Snippet: "fixed"}}
+
+	rows := compareEvidence(before, after)
+	if len(rows) != 2 {
+		t.Fatalf("compareEvidence() returned %d rows, want 2: %+v", len(rows), rows)
+	}
+	if rows[0].kind != rowSame || rows[1].kind != rowChanged {
+		t.Errorf("row kinds = %v, %v, want same, changed", rows[0].kind, rows[1].kind)
+	}
+}
+
+func TestCompareVerdict(t *testing.T) {
+	problem := &komodor.RCAPollResponse{IsComplete: true, ProblemShort: "OOMKilled"}
+
+	tests := []struct {
+		name  string
+		after *komodor.RCAPollResponse
+		want  compareVerdict
+	}{
+		{"running", &komodor.RCAPollResponse{}, verdictPending},
+		{"failed", &komodor.RCAPollResponse{IsFailed: true}, verdictFailed},
+		{"resolved", &komodor.RCAPollResponse{IsComplete: true}, verdictResolved},
+		{"unchanged", &komodor.RCAPollResponse{IsComplete: true, ProblemShort: "oomkilled"}, verdictUnchanged},
+		{"changed", &komodor.RCAPollResponse{IsComplete: true, ProblemShort: "Image pull failed"}, verdictChanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareResults(problem, tt.after).verdict; got != tt.want {
+				t.Errorf("verdict = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,13 +1,24 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+
+	"k9s-rca/komodor"
 )
 
 type BubbleTeaTUI struct {
@@ -20,57 +31,200 @@ func NewBubbleTeaTUI() *BubbleTeaTUI {
 }
 
 type rcaModel struct {
+	ctx        context.Context
+	cancel     context.CancelCauseFunc
 	config     *Config
 	sessionID  string
 	spinner    spinner.Model
-	results    *RCAPollResponse
+	viewport   viewport.Model
+	results    *komodor.RCAPollResponse
 	pollCount  int
 	err        error
 	isComplete bool
+	isFailed   bool
+	isStuck    bool
 	quitting   bool
+
+	// stuckPrompt pauses polling until the user picks an action for a
+	// stuck session; keepWaiting suppresses the prompt afterwards.
+	stuckPrompt  bool
+	keepWaiting  bool
+	retriggering bool
+
+	// quitPrompt asks whether to stop the session on the server before
+	// quitting a running analysis; stopping is set while that request runs.
+	// stopped and detached record the answer.
+	quitPrompt bool
+	stopping   bool
+	stopped    bool
+	detached   bool
+
+	// previous is the result a re-run is compared against on the Compare
+	// tab.
+	previous *komodor.RCAPollResponse
+
+	// offline models show stored results and never poll.
+	offline bool
+
+	// stream delivers session updates while the API streams them; without
+	// one the model polls. stopStream closes it.
+	stream     <-chan sessionUpdate
+	stopStream context.CancelFunc
+
+	// activeTab is the tab on screen; tabOffsets keeps the scroll position
+	// of every tab while another one is shown.
+	activeTab  rcaTab
+	tabOffsets [tabCount]int
+
+	// evidenceCursor is the selected item on the Evidence tab; Enter opens
+	// it in the inspector.
+	evidenceCursor int
+	inspector      *evidenceInspector
+
+	// ages, activity and lastChange track what each poll discovered.
+	ages       itemAges
+	activity   []activityEntry
+	lastChange time.Time
+
+	// chat is the follow-up conversation. While an answer streams in,
+	// chatPending holds it, chatStream delivers the next piece and
+	// stopChat abandons it.
+	chat        []ChatMessage
+	chatInput   textinput.Model
+	chatting    bool
+	chatPending string
+	chatStream  <-chan tea.Msg
+	stopChat    context.CancelFunc
+
+	// notice is a one-line status message, e.g. where a report was exported.
+	notice string
+	help   help.Model
+
+	// link is the Komodor web URL shown with the Link binding. It is printed
+	// again after exit, where it stays clickable.
+	link string
+
 	lastUpdate time.Time
-	retryCount int
-	maxRetries int
-	width      int
-	height     int
+	startedAt  time.Time
+
+	// triggeredAt and finishedAt bound the elapsed time shown; startedAt
+	// is when monitoring began and drives the timeout.
+	triggeredAt time.Time
+	finishedAt  time.Time
+	progress    progress.Model
+	retryCount  int
+	retryErr    error
+	width       int
+	height      int
 }
 
+type rcaTab int
+
+const (
+	tabSummary rcaTab = iota
+	tabWhatHappened
+	tabEvidence
+	tabOperations
+	tabCompare
+	tabChat
+	tabRaw
+	tabCount
+)
+
 type tickMsg time.Time
-type pollResultMsg *RCAPollResponse
+type pollResultMsg *komodor.RCAPollResponse
 type pollErrorMsg error
+type retriggerMsg *komodor.RCAResponse
+type retriggerErrorMsg error
+type highlightExpiredMsg struct{}
 
-func initialModel(config *Config, sessionID string) rcaModel {
+func initialModel(ctx context.Context, cancel context.CancelCauseFunc, config *Config, sessionID string) rcaModel {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 
 	return rcaModel{
+		ctx:        ctx,
+		cancel:     cancel,
 		config:     config,
 		sessionID:  sessionID,
 		spinner:    s,
+		viewport:   viewport.New(0, 0),
+		help:       help.New(),
+		chatInput:  newChatInput(),
 		lastUpdate: time.Now(),
-		maxRetries: 72,
-		results:    &RCAPollResponse{SessionID: sessionID},
+		startedAt:  time.Now(),
+		progress:   progress.New(progress.WithDefaultGradient(), progress.WithWidth(32)),
+
+		triggeredAt: time.Now(),
+		results:     &komodor.RCAPollResponse{SessionID: sessionID},
+	}
+}
+
+func offlineModel(ctx context.Context, cancel context.CancelCauseFunc, config *Config, entry *HistoryEntry, results *komodor.RCAPollResponse) rcaModel {
+	entryConfig := *config
+	applyHistoryResource(&entryConfig, entry)
+
+	m := initialModel(ctx, cancel, &entryConfig, entry.SessionID)
+	m.offline = true
+	m.results = results
+	m.isComplete = results.IsComplete
+	m.isFailed = results.IsFailed && !results.IsComplete
+	m.isStuck = results.IsStuck && !results.IsComplete
+	m.startedAt = entry.TriggeredAt
+	m.triggeredAt = entry.TriggeredAt
+	m.chat = entry.Chat
+	m.lastUpdate = entry.UpdatedAt
+	if entry.FinishedAt != nil {
+		m.finishedAt = *entry.FinishedAt
 	}
+	return m
 }
 
 func (m rcaModel) Init() tea.Cmd {
+	if m.offline {
+		return nil
+	}
 	return tea.Batch(
 		m.spinner.Tick,
-		tickCmd(),
-		pollRCACmd(m.config, m.sessionID),
+		m.watchCmd(),
 	)
 }
 
-func tickCmd() tea.Cmd {
-	return tea.Tick(2*time.Second, func(t time.Time) tea.Msg {
+// watchCmd starts following the session: over a stream when enabled,
+// otherwise with the first poll.
+func (m rcaModel) watchCmd() tea.Cmd {
+	if m.config.Stream {
+		return startStreamCmd(m.ctx, m.config, m.sessionID, m.startedAt.Add(monitorTimeout))
+	}
+	return pollRCACmd(m.ctx, m.config, m.sessionID)
+}
+
+// nextUpdateCmd waits for the stream's next update, or schedules the next
+// poll when there is no stream.
+func (m rcaModel) nextUpdateCmd() tea.Cmd {
+	if m.stream != nil {
+		return waitForUpdate(m.stream)
+	}
+	return tickCmd(m.config.PollInterval)
+}
+
+func (m *rcaModel) closeStream() {
+	if m.stopStream != nil {
+		m.stopStream()
+	}
+	m.stream, m.stopStream = nil, nil
+}
+
+func tickCmd(d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(t time.Time) tea.Msg {
 		return tickMsg(t)
 	})
 }
 
-func pollRCACmd(config *Config, sessionID string) tea.Cmd {
+func pollRCACmd(ctx context.Context, config *Config, sessionID string) tea.Cmd {
 	return func() tea.Msg {
-		result, err := fetchRCAStatus(config, sessionID)
+		result, err := config.Client.GetSession(ctx, sessionID)
 		if err != nil {
 			return pollErrorMsg(err)
 		}
@@ -78,216 +232,947 @@ func pollRCACmd(config *Config, sessionID string) tea.Cmd {
 	}
 }
 
+func retriggerCmd(ctx context.Context, config *Config) tea.Cmd {
+	return func() tea.Msg {
+		session, err := config.Client.CreateSession(ctx, config.rcaSession())
+		if err != nil {
+			return retriggerErrorMsg(err)
+		}
+		if session.SessionID == "" {
+			return retriggerErrorMsg(fmt.Errorf("no session ID received from Komodor API"))
+		}
+		return retriggerMsg(session)
+	}
+}
+
+// canRetrigger is false when monitoring a session by ID, where the resource
+// it was started for is unknown.
+func (m rcaModel) canRetrigger() bool {
+	return m.config.Kind != "" && m.config.Name != "" && m.config.KomodorClusterName != ""
+}
+
+// exportReport writes the current results to disk. --export-file is only
+// used when it was given for the same format.
+func (m rcaModel) exportReport(format string) rcaModel {
+	path := ""
+	configured, _ := exportExtension(m.config.ExportFormat)
+	requested, _ := exportExtension(format)
+	if configured == requested && m.config.ExportFile != "-" {
+		path = m.config.ExportFile
+	}
+
+	written, err := exportReport(format, path, newReportMeta(m.config, m.sessionID), m.results)
+	if err != nil {
+		logMessage("ERROR: Failed to export RCA report: %v", err)
+		m.notice = "❌ Export failed: " + err.Error()
+		return m
+	}
+	m.notice = "📄 Exported to " + written
+	return m
+}
+
+// trackChanges compares a poll result with the previous one and records
+// what is new. Results of the first poll are not highlighted. The returned
+// command re-renders once the highlights have expired.
+func (m *rcaModel) trackChanges(next *komodor.RCAPollResponse) tea.Cmd {
+	now := time.Now()
+	change := diffResults(m.results, next)
+	first := m.pollCount == 0
+
+	seen := now
+	if first {
+		seen = time.Time{}
+	}
+	m.ages.track(next, seen)
+
+	if change.Empty() {
+		return nil
+	}
+	m.lastChange = now
+	m.addActivity(now, change.String())
+	if first {
+		return nil
+	}
+	return tea.Tick(highlightDuration, func(time.Time) tea.Msg {
+		return highlightExpiredMsg{}
+	})
+}
+
+func (m *rcaModel) addActivity(at time.Time, text string) {
+	m.activity = append(m.activity, activityEntry{at: at, text: text})
+	if len(m.activity) > maxActivity {
+		m.activity = m.activity[len(m.activity)-maxActivity:]
+	}
+}
+
+// elapsed is the time since the session was triggered, frozen once it
+// finished.
+func (m rcaModel) elapsed() time.Duration {
+	end := time.Now()
+	switch {
+	case !m.finishedAt.IsZero():
+		end = m.finishedAt
+	case m.offline:
+		end = m.lastUpdate
+	}
+	return max(end.Sub(m.triggeredAt), 0)
+}
+
+// keyMap enables the bindings that apply to the current state.
+func (m rcaModel) keyMap() rcaKeyMap {
+	keys := newRCAKeyMap()
+	if m.quitPrompt {
+		return keys.quitPrompt()
+	}
+	keys.StopSession.SetEnabled(false)
+	keys.Detach.SetEnabled(false)
+	keys.Stay.SetEnabled(false)
+
+	hasData := m.pollCount > 0 || m.offline
+	selectable := m.activeTab == tabEvidence && len(m.results.EvidenceCollection) > 0
+
+	asking := m.activeTab == tabChat && m.canChat() && !m.chatting && !m.retriggering
+
+	keys.Inspect.SetEnabled(selectable)
+	keys.Ask.SetEnabled(asking)
+	keys.Exit.SetEnabled(m.finished() && !selectable && !asking)
+	if m.offline {
+		keys.Exit.SetHelp("enter", "back")
+	}
+	keys.Retrigger.SetEnabled(m.stuckPrompt && m.canRetrigger() && !m.chatting)
+	keys.KeepWaiting.SetEnabled(m.stuckPrompt)
+	keys.Rerun.SetEnabled(!m.offline && (m.isComplete || m.isFailed) && m.canRetrigger() && !m.chatting)
+
+	// Without --web-url the binding stays visible to say how to enable it.
+	keys.Link.SetEnabled(m.sessionID != "")
+	if sessionWebURL(m.config, m.sessionID) == "" {
+		keys.Link.SetHelp("o", "Komodor link (needs --web-url)")
+	}
+	keys.CopyProblem.SetEnabled(m.results.ProblemShort != "")
+	keys.CopyRecommendation.SetEnabled(m.results.Recommendation != "")
+	keys.CopyReport.SetEnabled(hasData)
+	keys.ExportMarkdown.SetEnabled(hasData)
+	keys.ExportHTML.SetEnabled(hasData)
+
+	if m.help.ShowAll {
+		keys.Help.SetHelp("?", "fewer keys")
+	}
+	if !m.finished() {
+		keys.Quit.SetHelp("q", "stop monitoring")
+	}
+	return keys
+}
+
+// textReport is the plain-text report copied with the CopyReport binding.
+func (m rcaModel) textReport() string {
+	var s strings.Builder
+	if err := writeTextReport(&s, m.results); err != nil {
+		return ""
+	}
+	if link := sessionWebURL(m.config, m.sessionID); link != "" {
+		fmt.Fprintf(&s, "\nKomodor: %s\n", link)
+	}
+	return s.String()
+}
+
+// finished reports whether polling has stopped for good.
+func (m rcaModel) finished() bool {
+	return m.offline || m.isComplete || m.isFailed || m.err != nil
+}
+
 func (m rcaModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if _, ok := msg.(spinner.TickMsg); !ok {
+		next.syncViewport()
+	}
+	return next, cmd
+}
+
+func (m rcaModel) update(msg tea.Msg) (rcaModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 		return m, nil
 
+	case tea.MouseMsg:
+		var cmd tea.Cmd
+		if m.inspector != nil {
+			m.inspector, cmd = m.inspector.Update(msg)
+			return m, cmd
+		}
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+
+	case clipboardMsg:
+		if msg.err != nil {
+			m.notice = "❌ Copy failed: " + msg.err.Error()
+		} else {
+			m.notice = fmt.Sprintf("📋 Copied %s to clipboard", msg.what)
+		}
+		return m, nil
+
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
-			m.quitting = true
+		if m.inspector != nil && msg.String() != "ctrl+c" {
+			var cmd tea.Cmd
+			m.inspector, cmd = m.inspector.Update(msg)
+			return m, cmd
+		}
+
+		if m.quitPrompt && msg.String() != "ctrl+c" {
+			return m.answerQuitPrompt(msg)
+		}
+
+		if m.chatInput.Focused() && msg.String() != "ctrl+c" {
+			switch msg.String() {
+			case "enter":
+				return m.sendQuestion()
+			case "esc":
+				m.chatInput.Blur()
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.chatInput, cmd = m.chatInput.Update(msg)
+			return m, cmd
+		}
+
+		keys := m.keyMap()
+		switch {
+		case key.Matches(msg, keys.Quit):
+			if m.canStopSession() && msg.String() != "ctrl+c" {
+				m.quitPrompt = true
+				m.notice = ""
+				return m, nil
+			}
+			if m.offline && msg.String() == "ctrl+c" {
+				// Leave the history browser too, not just this result.
+				m.cancel(errInterrupted)
+			}
+			return m.quit()
+		case key.Matches(msg, keys.Ask):
+			return m, m.chatInput.Focus()
+		case key.Matches(msg, keys.Inspect):
+			m.clampEvidenceCursor()
+			if m.evidenceCursor >= len(m.results.EvidenceCollection) {
+				return m, nil
+			}
+			m.inspector = newEvidenceInspector(m.results.EvidenceCollection[m.evidenceCursor], m.evidenceCursor, len(m.results.EvidenceCollection))
+			m.notice = ""
+			return m, nil
+		case key.Matches(msg, keys.Exit):
 			return m, tea.Quit
-		case "enter":
-			if m.isComplete || m.err != nil {
-				return m, tea.Quit
+		case key.Matches(msg, keys.Up) && keys.Inspect.Enabled():
+			m.moveEvidenceCursor(-1)
+			return m, nil
+		case key.Matches(msg, keys.Down) && keys.Inspect.Enabled():
+			m.moveEvidenceCursor(1)
+			return m, nil
+		case key.Matches(msg, keys.Retrigger):
+			logMessage("Session %s is stuck, re-triggering RCA", m.sessionID)
+			m.stuckPrompt = false
+			m.retriggering = true
+			return m, retriggerCmd(m.ctx, m.config)
+		case key.Matches(msg, keys.Rerun):
+			logMessage("Re-running RCA to compare against session %s", m.sessionID)
+			m.previous = m.results
+			m.retriggering = true
+			m.notice = ""
+			return m, retriggerCmd(m.ctx, m.config)
+		case key.Matches(msg, keys.KeepWaiting):
+			logMessage("Session %s is stuck, user chose to keep waiting", m.sessionID)
+			m.stuckPrompt = false
+			m.keepWaiting = true
+			if m.stream != nil {
+				// The stream is still open; polling as well would watch twice.
+				return m, waitForUpdate(m.stream)
+			}
+			return m, pollRCACmd(m.ctx, m.config, m.sessionID)
+		case key.Matches(msg, keys.ExportMarkdown):
+			return m.exportReport(exportMarkdown), nil
+		case key.Matches(msg, keys.ExportHTML):
+			return m.exportReport(exportHTML), nil
+		case key.Matches(msg, keys.CopyID):
+			return m, copyCmd("session ID", m.sessionID)
+		case key.Matches(msg, keys.CopyProblem):
+			return m, copyCmd("problem", m.results.ProblemShort)
+		case key.Matches(msg, keys.CopyRecommendation):
+			return m, copyCmd("recommendation", m.results.Recommendation)
+		case key.Matches(msg, keys.CopyReport):
+			return m, copyCmd("report", m.textReport())
+		case key.Matches(msg, keys.Link):
+			link := sessionWebURL(m.config, m.sessionID)
+			if link == "" {
+				m.notice = "🔗 Set --web-url or KOMODOR_WEB_URL to the web app URL of a session, with {session} where the ID goes"
+				return m, nil
 			}
+			m.link = link
+			m.notice = "🔗 " + m.link
+			return m, nil
+		case key.Matches(msg, keys.Help):
+			m.help.ShowAll = !m.help.ShowAll
+			return m, nil
+		case key.Matches(msg, keys.NextTab):
+			m.switchTab(m.activeTab + 1)
+			return m, nil
+		case key.Matches(msg, keys.PrevTab):
+			m.switchTab(m.activeTab - 1)
+			return m, nil
+		case key.Matches(msg, keys.JumpTab):
+			m.switchTab(rcaTab(msg.String()[0] - '1'))
+			return m, nil
+		case key.Matches(msg, keys.Top):
+			m.viewport.GotoTop()
+			return m, nil
+		case key.Matches(msg, keys.Bottom):
+			m.viewport.GotoBottom()
+			return m, nil
 		}
 
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
 		return m, cmd
 
 	case tickMsg:
-		m.lastUpdate = time.Time(msg)
-		if !m.isComplete && m.err == nil {
-			return m, tea.Batch(tickCmd(), pollRCACmd(m.config, m.sessionID))
+		if !m.finished() && !m.stuckPrompt && !m.retriggering {
+			return m, pollRCACmd(m.ctx, m.config, m.sessionID)
+		}
+		return m, nil
+
+	case retriggerMsg:
+		logMessage("✅ RCA re-triggered, new session ID: %s (was %s)", msg.SessionID, m.sessionID)
+		recordSessionResults(m.sessionID, m.results)
+		recordTriggeredSession(m.config, msg.SessionID)
+		m.savePartialAnswer()
+		m.closeStream()
+		m.closeChat()
+		m.sessionID = msg.SessionID
+		m.results = &komodor.RCAPollResponse{SessionID: msg.SessionID}
+		m.pollCount = 0
+		m.tabOffsets = [tabCount]int{}
+		m.evidenceCursor = 0
+		m.inspector = nil
+		m.ages = itemAges{}
+		m.chat = nil
+		m.lastChange = time.Time{}
+		m.addActivity(time.Now(), "re-triggered as session "+msg.SessionID)
+		m.viewport.GotoTop()
+		m.isComplete = false
+		m.isFailed = false
+		m.isStuck = false
+		m.keepWaiting = false
+		m.retriggering = false
+		m.startedAt = time.Now()
+		m.triggeredAt = time.Now()
+		m.finishedAt = time.Time{}
+		m.lastUpdate = time.Now()
+		return m, m.watchCmd()
+
+	case streamStartedMsg:
+		m.stream, m.stopStream = msg.updates, msg.stop
+		return m, waitForUpdate(m.stream)
+
+	case streamMsg:
+		if msg.updates != m.stream {
+			// Left over from a stream that was closed since.
+			return m, nil
+		}
+		if msg.results != nil {
+			return m.update(pollResultMsg(msg.results))
+		}
+		logStreamEnd(m.sessionID, msg.err)
+		m.closeStream()
+		if m.finished() || m.ctx.Err() != nil {
+			return m, nil
+		}
+		return m, pollRCACmd(m.ctx, m.config, m.sessionID)
+
+	case chatChunkMsg:
+		if msg.stream != m.chatStream {
+			// Left over from an answer that was abandoned since.
+			return m, nil
+		}
+		m.chatPending += msg.text
+		m.followChat()
+		return m, waitForChat(m.chatStream)
+
+	case chatDoneMsg:
+		if msg.stream != m.chatStream {
+			return m, nil
+		}
+		answer := msg.answer
+		if answer == "" {
+			answer = m.chatPending
+		}
+		m.closeChat()
+		if answer != "" {
+			message := ChatMessage{Role: chatRoleAssistant, Content: answer, At: time.Now(), Interrupted: msg.err != nil}
+			m.chat = append(m.chat, message)
+			recordChatMessage(m.sessionID, message)
+		}
+		if msg.err != nil {
+			logMessage("ERROR: Follow-up on session %s failed: %v", m.sessionID, msg.err)
+			m.notice = "❌ Follow-up failed: " + msg.err.Error()
+			if errors.Is(msg.err, komodor.ErrFollowUpUnsupported) {
+				m.notice = "❌ This Komodor API does not support follow-up questions"
+			}
 		}
-		return m, tickCmd()
+		m.followChat()
+		return m, nil
+
+	case highlightExpiredMsg:
+		// Nothing to do; the viewport is re-rendered after every message.
+		return m, nil
+
+	case sessionCancelledMsg:
+		m.stopping = false
+		if msg.err != nil {
+			logMessage("ERROR: Failed to stop session %s: %v", m.sessionID, msg.err)
+			m.notice = "❌ Could not stop the analysis: " + msg.err.Error()
+			if errors.Is(msg.err, komodor.ErrCancelUnsupported) {
+				m.notice = "❌ This Komodor API cannot stop sessions; press b to leave it running in the background"
+			}
+			return m, nil
+		}
+		logMessage("RCA session %s stopped on the server", m.sessionID)
+		m.stopped = true
+		recordSessionOutcome(m.sessionID, "cancelled")
+		return m.quit()
+
+	case retriggerErrorMsg:
+		logMessage("ERROR: Failed to re-trigger RCA: %v", msg)
+		m.retriggering = false
+		m.err = fmt.Errorf("failed to re-trigger RCA: %w", msg)
+		return m, nil
 
 	case pollResultMsg:
+		expire := m.trackChanges(msg)
 		m.results = msg
+		m.clampEvidenceCursor()
 		m.pollCount++
 		m.retryCount = 0
+		m.retryErr = nil
+		m.lastUpdate = time.Now()
 		m.isComplete = msg.IsComplete
+		m.isFailed = msg.IsFailed && !msg.IsComplete
+		m.isStuck = msg.IsStuck && !msg.IsComplete
+
+		if (m.isComplete || m.isFailed) && m.finishedAt.IsZero() {
+			m.finishedAt = m.lastUpdate
+			if m.previous != nil {
+				m.notice = compareResults(m.previous, msg).Summary() + " (see the Compare tab)"
+			}
+		}
 
-		if msg.IsComplete && msg.RawData != nil {
+		if (m.isComplete || m.isFailed) && msg.RawData != nil {
 			logRawRCAData("Final RCA Response", msg.RawData)
 		}
 
-		if m.pollCount > 300 {
-			m.err = fmt.Errorf("timeout reached (15 minutes)")
-			m.isComplete = true
+		if (m.isComplete || m.isFailed) && m.config.ExportFormat != "" && m.config.ExportFile != "-" {
+			m = m.exportReport(m.config.ExportFormat)
 		}
 
-		return m, nil
+		if m.isFailed {
+			logMessage("RCA session %s failed", m.sessionID)
+			m.closeStream()
+			return m, expire
+		}
+
+		if m.isStuck && !m.keepWaiting {
+			logMessage("RCA session %s is stuck, asking user how to proceed", m.sessionID)
+			m.stuckPrompt = true
+			return m, expire
+		}
+
+		if !m.isComplete && time.Since(m.startedAt) > monitorTimeout {
+			m.err = fmt.Errorf("%w: no result after %s", errTimedOut, monitorTimeout)
+			if m.isStuck {
+				m.err = fmt.Errorf("%w: still stuck after %s", errSessionStuck, monitorTimeout)
+			}
+		}
+
+		if m.finished() {
+			m.closeStream()
+			return m, expire
+		}
+		return m, tea.Batch(expire, m.nextUpdateCmd())
 
 	case pollErrorMsg:
-		m.retryCount++
-		if m.retryCount >= m.maxRetries {
+		if m.ctx.Err() != nil {
+			return m, nil
+		}
+		if !komodor.IsRetryable(msg) {
+			logMessage("ERROR: Poll failed permanently: %v", msg)
 			m.err = msg
-			m.isComplete = true
+			return m, nil
 		}
-		return m, nil
+		m.retryCount++
+		m.retryErr = msg
+		if m.retryCount > m.config.Retry.MaxRetries {
+			m.err = fmt.Errorf("failed to poll session after %d retries: %w", m.config.Retry.MaxRetries, msg)
+			return m, nil
+		}
+		wait := m.config.Retry.Backoff(m.retryCount, msg)
+		logMessage("Poll failed: %v (retry %d/%d in %s)", msg, m.retryCount, m.config.Retry.MaxRetries, wait)
+		return m, tickCmd(wait)
 	}
 
-	return m, nil
+	// Anything else, such as a cursor blinking, belongs to whichever input
+	// is active.
+	var cmd tea.Cmd
+	switch {
+	case m.inspector != nil:
+		m.inspector, cmd = m.inspector.Update(msg)
+	case m.chatInput.Focused():
+		m.chatInput, cmd = m.chatInput.Update(msg)
+	}
+	return m, cmd
 }
 
-func (m rcaModel) View() string {
-	if m.quitting {
-		return ""
-	}
+var (
+	titleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("86")).
+			Background(lipgloss.Color("235")).
+			Padding(0, 1).
+			MarginBottom(1)
 
-	var s strings.Builder
+	sectionStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("170")).
+			MarginTop(1)
 
-	titleStyle := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("86")).
-		Background(lipgloss.Color("235")).
-		Padding(0, 1).
-		MarginBottom(1)
+	labelStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241"))
 
-	headerStyle := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("170")).
-		MarginTop(1)
+	valueStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("255"))
 
-	labelStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("241"))
+	itemStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("250")).
+			PaddingLeft(2)
 
-	valueStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("255"))
+	successStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("46"))
 
-	itemStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("250")).
-		PaddingLeft(2)
+	metaBoxStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("62")).
+			Padding(0, 1)
 
-	errorStyle := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("196")).
-		Padding(1)
+	evidenceBoxStyle = lipgloss.NewStyle().
+				Border(lipgloss.NormalBorder()).
+				BorderForeground(lipgloss.Color("240")).
+				Padding(0, 1).
+				MarginLeft(2).
+				MarginBottom(1)
 
-	successStyle := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("46"))
+	evidenceQueryStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("117"))
+
+	evidenceSnippetStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("252")).
+				Italic(true)
+
+	newItemStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("48")).
+			PaddingLeft(2)
+
+	rawStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("252"))
+
+	tabStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("245")).
+			Padding(0, 1)
+
+	activeTabStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("86")).
+			Background(lipgloss.Color("237")).
+			Padding(0, 1)
+)
+
+// wrapTo word-wraps a style to the given width; 0 leaves lines as they are.
+func wrapTo(style lipgloss.Style, width int) lipgloss.Style {
+	if width <= 0 {
+		return style
+	}
+	return style.Width(width)
+}
+
+// syncViewport sizes the viewport to the space left between the header and
+// footer and refreshes its content, keeping the scroll position.
+func (m *rcaModel) syncViewport() {
+	if m.width == 0 || m.height == 0 {
+		return
+	}
+
+	if m.inspector != nil {
+		m.inspector.setSize(m.width, m.height-lipgloss.Height(m.headerView()))
+		return
+	}
+
+	height := m.height - lipgloss.Height(m.headerView()) - lipgloss.Height(m.footerView())
+	m.viewport.Width = m.width
+	m.viewport.Height = max(height, 1)
+	m.viewport.SetContent(m.tabView(m.activeTab, m.width))
+}
+
+func (m rcaModel) View() string {
+	if m.quitting {
+		return ""
+	}
 
 	if m.err != nil {
-		s.WriteString(errorStyle.Render("❌ Error: " + m.err.Error()))
-		s.WriteString("\n\n")
-		s.WriteString(labelStyle.Render("Press Enter or Ctrl+C to exit"))
-		return s.String()
+		return renderError("Error", m.err) + "\n\n" + labelStyle.Render("Press Enter or Ctrl+C to exit")
 	}
 
-	if m.isComplete {
+	if m.inspector != nil {
+		return m.headerView() + "\n" + m.inspector.View(m.notice)
+	}
+
+	body := m.viewport.View()
+	if m.width == 0 {
+		// No size yet, render everything unscrolled.
+		body = m.tabView(m.activeTab, 0)
+	}
+	return m.headerView() + "\n" + body + "\n" + m.footerView()
+}
+
+// headerView is the sticky part above the scrollable results.
+func (m rcaModel) headerView() string {
+	var s strings.Builder
+
+	switch {
+	case m.retriggering:
+		s.WriteString(titleStyle.Render(fmt.Sprintf("%s RE-TRIGGERING RCA ANALYSIS", m.spinner.View())))
+	case m.isComplete:
 		s.WriteString(titleStyle.Render("✅ RCA ANALYSIS COMPLETED"))
-	} else {
+	case m.isFailed:
+		s.WriteString(titleStyle.Foreground(lipgloss.Color("196")).Render("❌ RCA ANALYSIS FAILED"))
+	case m.isStuck:
+		s.WriteString(titleStyle.Foreground(lipgloss.Color("208")).Render("⚠️  RCA ANALYSIS STUCK"))
+	default:
 		s.WriteString(titleStyle.Render(fmt.Sprintf("%s RCA ANALYSIS IN PROGRESS", m.spinner.View())))
 	}
-	s.WriteString("\n\n")
+	s.WriteString("\n")
 
-	metaBox := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("62")).
-		Padding(0, 1)
+	phase := inferPhase(m.results)
+	timing := fmt.Sprintf("%s %s", labelStyle.Render("Elapsed:"), valueStyle.Render(formatDuration(m.elapsed())))
+	switch {
+	case !m.finishedAt.IsZero():
+		timing += fmt.Sprintf("   %s %s", labelStyle.Render("Finished:"), valueStyle.Render(m.finishedAt.Local().Format("15:04:05")))
+	case !m.finished():
+		remaining := max(monitorTimeout-time.Since(m.startedAt), 0)
+		timing += fmt.Sprintf("   %s %s", labelStyle.Render("Time left:"), valueStyle.Render(formatDuration(remaining)))
+	}
 
-	metaContent := fmt.Sprintf("%s %s\n%s %s\n%s %d | %s %s",
+	metaContent := fmt.Sprintf("%s %s\n%s %s   %s %s\n%s\n%s",
 		labelStyle.Render("Session ID:"),
 		valueStyle.Render(m.results.SessionID),
 		labelStyle.Render("Status:"),
 		m.getStatusView(),
-		labelStyle.Render("Poll Count:"),
-		m.pollCount,
-		labelStyle.Render("Last Update:"),
-		valueStyle.Render(m.lastUpdate.Format("15:04:05")),
+		labelStyle.Render("Phase:"),
+		valueStyle.Render(phase.Name),
+		timing,
+		m.progress.ViewAs(phase.Progress),
 	)
-	s.WriteString(metaBox.Render(metaContent))
+	s.WriteString(metaBoxStyle.Render(metaContent))
 	s.WriteString("\n")
-
-	if m.results.ProblemShort != "" {
-		s.WriteString(headerStyle.Render("📋 Problem"))
-		s.WriteString("\n")
-		s.WriteString(itemStyle.Render(m.results.ProblemShort))
+	if !m.offline {
+		s.WriteString(m.activityView())
 		s.WriteString("\n")
 	}
+	s.WriteString(m.tabBarView())
+	return s.String()
+}
 
-	if m.results.Recommendation != "" {
-		s.WriteString(headerStyle.Render("💡 Recommendation"))
-		s.WriteString("\n")
-		s.WriteString(itemStyle.Render(m.results.Recommendation))
-		s.WriteString("\n")
-	}
+// tabView renders the content of one tab, word-wrapped to width.
+func (m rcaModel) tabView(tab rcaTab, width int) string {
+	var s strings.Builder
 
-	s.WriteString(headerStyle.Render("📝 What Happened"))
-	s.WriteString("\n")
-	if len(m.results.WhatHappened) > 0 {
+	item := wrapTo(itemStyle, width)
+	newItem := wrapTo(newItemStyle, width)
+	waiting := item.Render(labelStyle.Render("⏳ Waiting for data..."))
+
+	switch tab {
+	case tabSummary:
+		if m.results.ProblemShort == "" && m.results.Recommendation == "" {
+			s.WriteString(waiting)
+			s.WriteString("\n")
+		}
+		if m.results.ProblemShort != "" {
+			s.WriteString(sectionStyle.Render("📋 Problem"))
+			s.WriteString("\n")
+			s.WriteString(item.Render(m.results.ProblemShort))
+			s.WriteString("\n")
+		}
+		if m.results.Recommendation != "" {
+			s.WriteString(sectionStyle.Render("💡 Recommendation"))
+			s.WriteString("\n")
+			s.WriteString(item.Render(m.results.Recommendation))
+			s.WriteString("\n")
+		}
+		if len(m.activity) > 0 && !m.offline {
+			s.WriteString(sectionStyle.Render("🕑 Activity"))
+			s.WriteString("\n")
+			for i := len(m.activity) - 1; i >= 0; i-- {
+				entry := m.activity[i]
+				s.WriteString(item.Render(labelStyle.Render(entry.at.Format("15:04:05")) + "  " + entry.text))
+				s.WriteString("\n")
+			}
+		}
+
+	case tabWhatHappened:
+		if len(m.results.WhatHappened) == 0 {
+			s.WriteString(waiting)
+			break
+		}
 		for i, event := range m.results.WhatHappened {
-			s.WriteString(itemStyle.Render(fmt.Sprintf("%d. %s", i+1, event)))
+			style := item
+			if isNew(m.ages.whatHappened, i) {
+				style = newItem
+			}
+			s.WriteString(style.Render(fmt.Sprintf("%d. %s", i+1, event)))
 			s.WriteString("\n")
 		}
-	} else {
-		s.WriteString(itemStyle.Render(labelStyle.Render("⏳ Waiting for data...")))
-		s.WriteString("\n")
-	}
 
-	s.WriteString(headerStyle.Render("🔍 Evidence"))
-	s.WriteString("\n")
-	if len(m.results.EvidenceCollection) > 0 {
-		evidenceBoxStyle := lipgloss.NewStyle().
-			Border(lipgloss.NormalBorder()).
-			BorderForeground(lipgloss.Color("240")).
-			Padding(0, 1).
-			MarginLeft(2).
-			MarginBottom(1)
+	case tabEvidence:
+		if len(m.results.EvidenceCollection) == 0 {
+			s.WriteString(waiting)
+			break
+		}
+		for i := range m.results.EvidenceCollection {
+			s.WriteString(m.evidenceBoxView(i, width))
+			s.WriteString("\n")
+		}
 
-		evidenceQueryStyle := lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("117"))
-
-		evidenceSnippetStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("252")).
-			Italic(true)
-
-		for i, evidence := range m.results.EvidenceCollection {
-			evidenceContent := fmt.Sprintf("%s\n%s",
-				evidenceQueryStyle.Render(fmt.Sprintf("%d. %s", i+1, evidence.Query)),
-				evidenceSnippetStyle.Render("   → "+evidence.Snippet),
-			)
-			s.WriteString(evidenceBoxStyle.Render(evidenceContent))
+	case tabOperations:
+		if len(m.results.Operations) == 0 {
+			s.WriteString(waiting)
+			break
+		}
+		for i, operation := range m.results.Operations {
+			style := item
+			if isNew(m.ages.operations, i) {
+				style = newItem
+			}
+			s.WriteString(style.Render(fmt.Sprintf("%d. %s", i+1, operation)))
 			s.WriteString("\n")
 		}
+
+	case tabCompare:
+		if m.previous == nil {
+			hint := wrapTo(itemStyle.Foreground(lipgloss.Color("241")), width)
+			s.WriteString(hint.Render("🔁 Once the analysis finishes, press r to run it again, for example after a fix, and compare the two results here."))
+			break
+		}
+		s.WriteString(renderComparison(compareResults(m.previous, m.results), width))
+
+	case tabChat:
+		s.WriteString(m.chatView(width))
+
+	case tabRaw:
+		if m.results.RawData == nil {
+			s.WriteString(waiting)
+			break
+		}
+		data, err := json.MarshalIndent(m.results.RawData, "", "  ")
+		if err != nil {
+			s.WriteString(item.Render(fmt.Sprintf("❌ Failed to encode payload: %v", err)))
+			break
+		}
+		s.WriteString(wrapTo(rawStyle, width).Render(string(data)))
+	}
+
+	return strings.TrimSuffix(s.String(), "\n")
+}
+
+// evidenceBoxView renders one evidence item as shown in the list. The
+// selected item only changes the border, so selecting never reflows.
+func (m rcaModel) evidenceBoxView(i, width int) string {
+	evidence := m.results.EvidenceCollection[i]
+
+	// The margin and border sit outside the wrapped width.
+	box := evidenceBoxStyle
+	if width > 0 {
+		box = box.Width(max(width-4, 10))
+	}
+	if i == m.evidenceCursor {
+		box = box.Border(lipgloss.ThickBorder()).BorderForeground(lipgloss.Color("86"))
+	} else if isNew(m.ages.evidence, i) {
+		box = box.BorderForeground(newItemStyle.GetForeground())
+	}
+
+	snippet := evidence.Snippet
+	if first, _, more := strings.Cut(snippet, "\n"); more {
+		snippet = first + " …"
+	}
+	content := fmt.Sprintf("%s\n%s",
+		evidenceQueryStyle.Render(fmt.Sprintf("%d. %s", i+1, evidence.Query)),
+		evidenceSnippetStyle.Render("   → "+snippet),
+	)
+	return box.Render(content)
+}
+
+// clampEvidenceCursor keeps the cursor on an existing item when a newer
+// result has less evidence.
+func (m *rcaModel) clampEvidenceCursor() {
+	m.evidenceCursor = max(min(m.evidenceCursor, len(m.results.EvidenceCollection)-1), 0)
+}
+
+// moveEvidenceCursor selects another evidence item and scrolls it into view.
+func (m *rcaModel) moveEvidenceCursor(delta int) {
+	m.evidenceCursor += delta
+	m.clampEvidenceCursor()
+	m.syncViewport()
+
+	top := 0
+	for i := 0; i < m.evidenceCursor; i++ {
+		top += lipgloss.Height(m.evidenceBoxView(i, m.width))
+	}
+	bottom := top + lipgloss.Height(m.evidenceBoxView(m.evidenceCursor, m.width))
+
+	switch {
+	case top < m.viewport.YOffset:
+		m.viewport.SetYOffset(top)
+	case bottom > m.viewport.YOffset+m.viewport.Height:
+		m.viewport.SetYOffset(bottom - m.viewport.Height)
+	}
+}
+
+func (m rcaModel) tabLabel(tab rcaTab) string {
+	switch tab {
+	case tabSummary:
+		return "Summary"
+	case tabWhatHappened:
+		return fmt.Sprintf("What Happened (%d)%s", len(m.results.WhatHappened), newBadge(m.ages.whatHappened))
+	case tabEvidence:
+		return fmt.Sprintf("Evidence (%d)%s", len(m.results.EvidenceCollection), newBadge(m.ages.evidence))
+	case tabOperations:
+		return fmt.Sprintf("Operations (%d)%s", len(m.results.Operations), newBadge(m.ages.operations))
+	case tabCompare:
+		return "Compare"
+	case tabChat:
+		return fmt.Sprintf("Chat (%d)", len(m.chat))
+	default:
+		return "Raw JSON"
+	}
+}
+
+// newBadge marks tabs with recently added items, e.g. " +2".
+func newBadge(ages []time.Time) string {
+	if n := countNew(ages); n > 0 {
+		return fmt.Sprintf(" +%d", n)
+	}
+	return ""
+}
+
+// activityView is a one-line feed of the latest change and how long ago
+// anything changed.
+func (m rcaModel) activityView() string {
+	var line string
+	if len(m.activity) == 0 || m.lastChange.IsZero() {
+		line = labelStyle.Render("⚡ No changes yet")
 	} else {
-		s.WriteString(itemStyle.Render(labelStyle.Render("⏳ Waiting for data...")))
-		s.WriteString("\n")
+		latest := m.activity[len(m.activity)-1]
+		line = labelStyle.Render("⚡ ") + valueStyle.Render(latest.text) +
+			labelStyle.Render(fmt.Sprintf("  •  last change %s ago", formatDuration(time.Since(m.lastChange))))
 	}
+	if m.width > 0 {
+		line = lipgloss.NewStyle().MaxWidth(m.width).Render(line)
+	}
+	return line
+}
 
-	if !m.isComplete {
-		s.WriteString(headerStyle.Render("📊 Operations"))
-		s.WriteString("\n")
-		if len(m.results.Operations) > 0 {
-			for i, operation := range m.results.Operations {
-				s.WriteString(itemStyle.Render(fmt.Sprintf("%d. %s", i+1, operation)))
-				s.WriteString("\n")
-			}
+func (m rcaModel) tabBarView() string {
+	tabs := make([]string, tabCount)
+	for tab := range tabCount {
+		// Non-breaking spaces keep a label on one line when the bar wraps.
+		label := strings.ReplaceAll(fmt.Sprintf("%d %s", tab+1, m.tabLabel(tab)), " ", "\u00a0")
+		if tab == m.activeTab {
+			tabs[tab] = activeTabStyle.Render(label)
 		} else {
-			s.WriteString(itemStyle.Render(labelStyle.Render("⏳ Waiting for data...")))
-			s.WriteString("\n")
+			tabs[tab] = tabStyle.Render(label)
 		}
 	}
+	return wrapTo(lipgloss.NewStyle(), m.width).Render(strings.Join(tabs, labelStyle.Render("│")))
+}
 
-	s.WriteString("\n")
-	if m.isComplete {
+// switchTab remembers the scroll position of the current tab and restores
+// the one of the new tab.
+func (m *rcaModel) switchTab(tab rcaTab) {
+	tab = (tab + tabCount) % tabCount
+	m.tabOffsets[m.activeTab] = m.viewport.YOffset
+	m.activeTab = tab
+	m.syncViewport()
+	m.viewport.SetYOffset(m.tabOffsets[tab])
+}
+
+// footerView is the sticky part below the scrollable results. It is wrapped
+// to the window so its height can be measured, and must not depend on the
+// scroll position.
+func (m rcaModel) footerView() string {
+	var s strings.Builder
+
+	if m.notice != "" {
+		s.WriteString(valueStyle.Render(m.notice))
+		s.WriteString("\n")
+	}
+	switch {
+	case m.isComplete:
 		s.WriteString(successStyle.Render("✓ Analysis Complete"))
 		s.WriteString("\n")
-		s.WriteString(labelStyle.Render("Press Enter or Ctrl+C to exit"))
+	case m.isFailed:
+		s.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("196")).Render("✗ Komodor reported that this analysis failed"))
+		s.WriteString("\n")
+	case m.offline:
+		s.WriteString(labelStyle.Render(fmt.Sprintf("Saved result from %s", m.lastUpdate.Local().Format("2006-01-02 15:04"))))
+		s.WriteString("\n")
+	case m.stopping:
+		s.WriteString(labelStyle.Render("⏳ Stopping the analysis on the Komodor server..."))
+		s.WriteString("\n")
+	case m.quitPrompt:
+		s.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("208")).Render("The analysis is still running. Stop it on the Komodor server too?"))
+		s.WriteString("\n")
+	case m.stuckPrompt:
+		s.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("208")).Render("⚠️  The analysis is not making progress"))
+		s.WriteString("\n")
+	}
+
+	if m.activeTab == tabChat && m.canChat() {
+		input := m.chatInput
+		input.Width = max(m.width-6, 10)
+		if m.chatting {
+			input.Placeholder = "Waiting for the answer..."
+		}
+		s.WriteString(input.View())
+		s.WriteString("\n")
+	}
+
+	// The scroll position sits at the end of the first help line, which is
+	// kept short enough to leave room for it.
+	h := m.help
+	scroll := ""
+	if m.width > 0 {
+		scroll = labelStyle.Render(fmt.Sprintf("  %3.0f%%", m.viewport.ScrollPercent()*100))
+		h.Width = max(m.width-lipgloss.Width(scroll), 1)
+	}
+	helpView := h.View(m.keyMap())
+	if first, rest, ok := strings.Cut(helpView, "\n"); ok {
+		s.WriteString(first + scroll + "\n" + rest)
 	} else {
-		s.WriteString(labelStyle.Render("Press Ctrl+C to stop monitoring"))
+		s.WriteString(helpView + scroll)
 	}
 
-	return s.String()
+	return wrapTo(lipgloss.NewStyle(), m.width).Render(s.String())
 }
 
 func (m rcaModel) getStatusView() string {
@@ -297,21 +1182,66 @@ func (m rcaModel) getStatusView() string {
 			Foreground(lipgloss.Color("46")).
 			Render("✅ Complete")
 	}
+	if m.isFailed {
+		return lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("196")).
+			Render("❌ Failed")
+	}
+	if m.isStuck {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color("208")).
+			Render("⚠️  Stuck")
+	}
+	if m.retryErr != nil {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color("208")).
+			Render(fmt.Sprintf("⚠️  Retrying (%d/%d)", m.retryCount, m.config.Retry.MaxRetries))
+	}
 	return lipgloss.NewStyle().
 		Foreground(lipgloss.Color("226")).
 		Render("⏳ In Progress")
 }
 
-func (b *BubbleTeaTUI) MonitorRCA(config *Config, sessionID string) error {
+func (b *BubbleTeaTUI) MonitorRCA(ctx context.Context, config *Config, sessionID string) error {
 	b.config = config
 	b.sessionID = sessionID
 
+	monitorCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	initial := initialModel(monitorCtx, cancel, config, sessionID)
+	if entry, err := loadHistoryEntry(sessionID); err == nil {
+		initial.triggeredAt = entry.TriggeredAt
+		initial.chat = entry.Chat
+	}
+
 	p := tea.NewProgram(
-		initialModel(config, sessionID),
+		initial,
 		tea.WithAltScreen(),
+		tea.WithMouseCellMotion(),
+		tea.WithContext(monitorCtx),
 	)
 
 	model, err := p.Run()
+	if finalModel, ok := model.(rcaModel); ok {
+		printLink(finalModel.link)
+		finalModel.savePartialAnswer()
+		if finalModel.detached {
+			fmt.Fprintf(os.Stderr, "🔄 RCA session %s keeps running. Follow it with: k9s-rca watch %s\n", finalModel.sessionID, finalModel.sessionID)
+		}
+		recordSessionResults(finalModel.sessionID, finalModel.results)
+		if config.ExportFile == "-" && (finalModel.isComplete || finalModel.isFailed) {
+			exportConfigured(config, finalModel.results)
+		}
+	}
+	if cause := context.Cause(monitorCtx); cause != nil {
+		logMessage("RCA monitoring stopped: %v", cause)
+		return cause
+	}
+	if errors.Is(err, tea.ErrInterrupted) {
+		return errInterrupted
+	}
 	if err != nil {
 		return fmt.Errorf("error running TUI: %w", err)
 	}
@@ -320,26 +1250,103 @@ func (b *BubbleTeaTUI) MonitorRCA(config *Config, sessionID string) error {
 	if finalModel.err != nil {
 		return finalModel.err
 	}
+	if finalModel.isFailed {
+		return fmt.Errorf("%w: session %s", errSessionFailed, finalModel.sessionID)
+	}
 
 	return nil
 }
 
+// ShowRCAResults displays stored results without contacting the API.
+func (b *BubbleTeaTUI) ShowRCAResults(ctx context.Context, config *Config, entry *HistoryEntry) error {
+	results, err := entry.Results()
+	if err != nil {
+		return err
+	}
+
+	viewCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	p := tea.NewProgram(
+		offlineModel(viewCtx, cancel, config, entry, results),
+		tea.WithAltScreen(),
+		tea.WithMouseCellMotion(),
+		tea.WithContext(viewCtx),
+	)
+
+	model, err := p.Run()
+	if finalModel, ok := model.(rcaModel); ok {
+		printLink(finalModel.link)
+		finalModel.savePartialAnswer()
+	}
+	if cause := context.Cause(viewCtx); cause != nil {
+		return cause
+	}
+	if err != nil {
+		if errors.Is(err, tea.ErrInterrupted) {
+			return errInterrupted
+		}
+		return fmt.Errorf("error running TUI: %w", err)
+	}
+	return nil
+}
+
+// printLink repeats a deep link shown in the TUI once the alternate screen
+// is gone. It goes to stderr so stdout exports stay clean.
+func printLink(link string) {
+	if link != "" {
+		fmt.Fprintf(os.Stderr, "🔗 Komodor session: %s\n", link)
+	}
+}
+
 func (b *BubbleTeaTUI) ClearScreen() {
 }
 
-func (b *BubbleTeaTUI) DisplayLiveRCAResults(results *RCAPollResponse, pollCount int) {
+func (b *BubbleTeaTUI) DisplayLiveRCAResults(results *komodor.RCAPollResponse, pollCount int) {
 }
 
-func (b *BubbleTeaTUI) DisplayFinalRCAResults(results *RCAPollResponse) {
+func (b *BubbleTeaTUI) DisplayFinalRCAResults(results *komodor.RCAPollResponse) {
 }
 
 func (b *BubbleTeaTUI) DisplayError(message string, err error) {
+	fmt.Println(renderError(message, err))
+}
+
+func renderError(message string, err error) string {
 	errorStyle := lipgloss.NewStyle().
 		Bold(true).
 		Foreground(lipgloss.Color("196")).
 		Padding(1)
 
-	fmt.Println(errorStyle.Render(fmt.Sprintf("❌ %s: %v", message, err)))
+	guidance, ok := guidanceFor(err)
+	if !ok {
+		return errorStyle.Render(fmt.Sprintf("❌ %s: %v", message, err))
+	}
+
+	detailStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("250")).
+		PaddingLeft(1)
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("226")).
+		PaddingLeft(1)
+
+	var s strings.Builder
+	s.WriteString(errorStyle.Render(fmt.Sprintf("❌ %s: %s", message, guidance.Summary)))
+	s.WriteString("\n")
+
+	var apiErr *komodor.APIError
+	if errors.As(err, &apiErr) {
+		detail := fmt.Sprintf("HTTP %d: %s", apiErr.StatusCode, apiErr.Message)
+		if apiErr.RequestID != "" {
+			detail += fmt.Sprintf(" (request ID: %s)", apiErr.RequestID)
+		}
+		s.WriteString(detailStyle.Render(detail))
+		s.WriteString("\n")
+	}
+
+	s.WriteString(hintStyle.Render("💡 " + guidance.Hint))
+	return s.String()
 }
 
 func (b *BubbleTeaTUI) DisplayMessage(message string) {
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"gopkg.in/yaml.v3"
+
+	"k9s-rca/komodor"
+)
+
+const (
+	snippetJSON = "JSON"
+	snippetYAML = "YAML"
+	snippetLog  = "log"
+	snippetText = "text"
+)
+
+var (
+	gutterStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	matchGutterStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("226")).Bold(true)
+	matchStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("226"))
+	keyStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("117"))
+	stringStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("114"))
+	numberStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("215"))
+	keywordStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("170"))
+	commentStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Italic(true)
+	timestampStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+	errorLevelStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+	warnLevelStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Bold(true)
+	infoLevelStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+
+	jsonTokenPattern = regexp.MustCompile(`"(?:[^"\\]|\\.)*"(\s*:)?|-?\d+(?:\.\d+)?(?:[eE][+-]?\d+)?|\b(?:true|false|null)\b`)
+	yamlKeyPattern   = regexp.MustCompile(`^(\s*(?:- )?)([^\s#:-][^:#]*?)(:)(\s|$)`)
+	yamlCommentStart = regexp.MustCompile(`(^|\s)#`)
+	logLevelPattern  = regexp.MustCompile(`(?i)\b(fatal|panic|error|err|warn|warning|info|debug|trace)\b`)
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?`)
+)
+
+// evidenceInspector shows one evidence snippet in full, with highlighting
+// and search. It is nil on rcaModel while the evidence list is shown.
+type evidenceInspector struct {
+	evidence komodor.Evidence
+	index    int
+	total    int
+	kind     string
+	lines    []string
+
+	viewport viewport.Model
+	search   textinput.Model
+	query    *regexp.Regexp
+	matches  []int
+	match    int
+	width    int
+}
+
+func newEvidenceInspector(evidence komodor.Evidence, index, total int) *evidenceInspector {
+	kind, text := detectSnippet(evidence.Snippet)
+
+	search := textinput.New()
+	search.Prompt = "/"
+	search.Placeholder = "search"
+
+	vp := viewport.New(0, 0)
+	vp.SetHorizontalStep(4)
+
+	return &evidenceInspector{
+		evidence: evidence,
+		index:    index,
+		total:    total,
+		kind:     kind,
+		lines:    strings.Split(text, "\n"),
+		viewport: vp,
+		search:   search,
+	}
+}
+
+// detectSnippet guesses what a snippet contains. JSON is re-indented so it
+// reads the same as the YAML and log views.
+func detectSnippet(snippet string) (string, string) {
+	snippet = strings.TrimRight(strings.ReplaceAll(snippet, "\r\n", "\n"), "\n")
+	trimmed := strings.TrimSpace(snippet)
+
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var buf bytes.Buffer
+		if json.Indent(&buf, []byte(trimmed), "", "  ") == nil {
+			return snippetJSON, buf.String()
+		}
+	}
+
+	if strings.Contains(trimmed, "\n") && strings.Contains(trimmed, ":") {
+		var doc interface{}
+		if yaml.Unmarshal([]byte(snippet), &doc) == nil {
+			switch doc.(type) {
+			case map[string]interface{}, []interface{}:
+				return snippetYAML, snippet
+			}
+		}
+	}
+
+	lines := strings.Split(trimmed, "\n")
+	logLines := 0
+	for _, line := range lines {
+		if timestampPattern.MatchString(line) || logLevelPattern.MatchString(line) {
+			logLines++
+		}
+	}
+	if logLines > 0 && logLines*2 >= len(lines) {
+		return snippetLog, snippet
+	}
+	return snippetText, snippet
+}
+
+func highlightLine(kind, line string) string {
+	switch kind {
+	case snippetJSON:
+		return jsonTokenPattern.ReplaceAllStringFunc(line, func(token string) string {
+			switch {
+			case strings.HasSuffix(token, ":"):
+				key := strings.TrimRight(strings.TrimSuffix(token, ":"), " \t")
+				return keyStyle.Render(key) + token[len(key):]
+			case strings.HasPrefix(token, `"`):
+				return stringStyle.Render(token)
+			case token == "true" || token == "false" || token == "null":
+				return keywordStyle.Render(token)
+			default:
+				return numberStyle.Render(token)
+			}
+		})
+
+	case snippetYAML:
+		comment := ""
+		if loc := yamlCommentStart.FindStringIndex(line); loc != nil && !strings.ContainsAny(line[:loc[0]], `"'`) {
+			start := loc[1] - 1
+			line, comment = line[:start], commentStyle.Render(line[start:])
+		}
+		if m := yamlKeyPattern.FindStringSubmatchIndex(line); m != nil {
+			line = line[:m[4]] + keyStyle.Render(line[m[4]:m[5]]) + line[m[5]:]
+		}
+		return line + comment
+
+	case snippetLog:
+		line = timestampPattern.ReplaceAllStringFunc(line, func(ts string) string {
+			return timestampStyle.Render(ts)
+		})
+		return logLevelPattern.ReplaceAllStringFunc(line, func(level string) string {
+			switch strings.ToLower(level) {
+			case "fatal", "panic", "error", "err":
+				return errorLevelStyle.Render(level)
+			case "warn", "warning":
+				return warnLevelStyle.Render(level)
+			default:
+				return infoLevelStyle.Render(level)
+			}
+		})
+	}
+	return line
+}
+
+// highlightMatches marks every search hit. Syntax colors are dropped on
+// matching lines so the hits stand out.
+func highlightMatches(line string, query *regexp.Regexp) string {
+	var s strings.Builder
+	last := 0
+	for _, loc := range query.FindAllStringIndex(line, -1) {
+		s.WriteString(line[last:loc[0]])
+		s.WriteString(matchStyle.Render(line[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	s.WriteString(line[last:])
+	return s.String()
+}
+
+func (e *evidenceInspector) setSize(width, height int) {
+	e.width = width
+	e.viewport.Width = width
+	// One line of title above, two lines of status and hints below.
+	e.viewport.Height = max(height-3, 1)
+	e.render()
+}
+
+func (e *evidenceInspector) render() {
+	matching := make(map[int]bool, len(e.matches))
+	for _, i := range e.matches {
+		matching[i] = true
+	}
+	current := -1
+	if len(e.matches) > 0 {
+		current = e.matches[e.match]
+	}
+
+	digits := len(fmt.Sprint(len(e.lines)))
+	var s strings.Builder
+	for i, line := range e.lines {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		gutter := gutterStyle.Render(fmt.Sprintf(" %*d │ ", digits, i+1))
+		if i == current {
+			gutter = matchGutterStyle.Render(fmt.Sprintf("▶%*d │ ", digits, i+1))
+		}
+
+		s.WriteString(gutter)
+		if matching[i] {
+			s.WriteString(highlightMatches(line, e.query))
+		} else {
+			s.WriteString(highlightLine(e.kind, line))
+		}
+		if i < len(e.lines)-1 {
+			s.WriteString("\n")
+		}
+	}
+	e.viewport.SetContent(s.String())
+}
+
+// setQuery finds every line matching the search, case-insensitively.
+func (e *evidenceInspector) setQuery(query string) {
+	e.query = nil
+	e.matches = nil
+	e.match = 0
+	if query != "" {
+		e.query = regexp.MustCompile("(?i)" + regexp.QuoteMeta(query))
+		for i, line := range e.lines {
+			if e.query.MatchString(line) {
+				e.matches = append(e.matches, i)
+			}
+		}
+	}
+	e.render()
+	e.showMatch()
+}
+
+func (e *evidenceInspector) nextMatch(delta int) {
+	if len(e.matches) == 0 {
+		return
+	}
+	e.match = (e.match + delta + len(e.matches)) % len(e.matches)
+	e.render()
+	e.showMatch()
+}
+
+// showMatch scrolls the current match to the middle of the pane.
+func (e *evidenceInspector) showMatch() {
+	if len(e.matches) == 0 {
+		return
+	}
+	e.viewport.SetYOffset(e.matches[e.match] - e.viewport.Height/2)
+}
+
+// Update handles input for the pane. It returns nil once the pane is closed.
+func (e *evidenceInspector) Update(msg tea.Msg) (*evidenceInspector, tea.Cmd) {
+	var cmd tea.Cmd
+
+	keyMsg, isKey := msg.(tea.KeyMsg)
+	if e.search.Focused() {
+		if isKey {
+			switch keyMsg.String() {
+			case "enter":
+				e.search.Blur()
+				return e, nil
+			case "esc":
+				e.search.Blur()
+				e.search.Reset()
+				e.setQuery("")
+				return e, nil
+			}
+		}
+		e.search, cmd = e.search.Update(msg)
+		e.setQuery(e.search.Value())
+		return e, cmd
+	}
+
+	if !isKey {
+		e.viewport, cmd = e.viewport.Update(msg)
+		return e, cmd
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q", "backspace":
+		return nil, nil
+	case "/":
+		return e, e.search.Focus()
+	case "n":
+		e.nextMatch(1)
+		return e, nil
+	case "N":
+		e.nextMatch(-1)
+		return e, nil
+	case "c", "y":
+		return e, copyCmd("snippet", e.evidence.Snippet)
+	case "home", "g":
+		e.viewport.GotoTop()
+		return e, nil
+	case "end", "G":
+		e.viewport.GotoBottom()
+		return e, nil
+	}
+
+	e.viewport, cmd = e.viewport.Update(keyMsg)
+	return e, cmd
+}
+
+// View renders the pane; notice is shown in the status line when there is
+// no search to report on.
+func (e *evidenceInspector) View(notice string) string {
+	line := lipgloss.NewStyle().MaxWidth(max(e.width, 1))
+
+	title := evidenceQueryStyle.Render(fmt.Sprintf("Evidence %d/%d", e.index+1, e.total)) +
+		labelStyle.Render(fmt.Sprintf(" [%s] ", e.kind)) +
+		valueStyle.Render(e.evidence.Query)
+
+	var status string
+	switch {
+	case e.search.Focused():
+		status = e.search.View()
+	case e.query != nil && len(e.matches) == 0:
+		status = labelStyle.Render(fmt.Sprintf("No matches for %q", e.search.Value()))
+	case e.query != nil:
+		status = labelStyle.Render(fmt.Sprintf("Match %d/%d for %q", e.match+1, len(e.matches), e.search.Value()))
+	default:
+		status = valueStyle.Render(notice)
+	}
+
+	hints := labelStyle.Render("↑/↓ scroll  •  ←/→ pan  •  / search  •  n/N next/prev  •  c copy  •  esc back")
+
+	return line.Render(title) + "\n" + e.viewport.View() + "\n" + line.Render(status) + "\n" + line.Render(hints)
+}
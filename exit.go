@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	exitCodeOK          = 0
+	exitCodeError       = 1
+	exitCodeFailed      = 2
+	exitCodeStuck       = 3
+	exitCodeCancelled   = 4
+	exitCodeTimeout     = 124
+	exitCodeInterrupted = 130
+)
+
+var (
+	errInterrupted = errors.New("interrupted")
+	errTimedOut    = errors.New("timed out")
+
+	errSessionFailed = errors.New("RCA session failed")
+	errSessionStuck  = errors.New("RCA session is stuck")
+
+	errSessionCancelled = errors.New("RCA session cancelled")
+)
+
+func exitCodeFor(err error) int {
+	switch {
+	case err == nil:
+		return exitCodeOK
+	case errors.Is(err, errSessionFailed):
+		return exitCodeFailed
+	case errors.Is(err, errSessionStuck):
+		return exitCodeStuck
+	case errors.Is(err, errSessionCancelled):
+		return exitCodeCancelled
+	case errors.Is(err, errTimedOut):
+		return exitCodeTimeout
+	case errors.Is(err, errInterrupted):
+		return exitCodeInterrupted
+	default:
+		return exitCodeError
+	}
+}
+
+// signalContext is cancelled with errInterrupted on SIGINT or SIGTERM.
+func signalContext() (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			logMessage("Received signal %v, cancelling", sig)
+			cancel(errInterrupted)
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel(nil)
+	}
+}
+
+// commandContext applies the global --timeout flag to the command context.
+func commandContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if timeout > 0 {
+		return context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%w after %s", errTimedOut, timeout))
+	}
+	return context.WithCancel(ctx)
+}
+
+// withContextCause attaches the reason ctx was cancelled to err, so callers
+// can tell an interrupt or timeout apart from an ordinary failure.
+func withContextCause(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	cause := context.Cause(ctx)
+	if cause == nil || errors.Is(err, cause) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", cause, err)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"k9s-rca/komodor"
+)
+
+const (
+	exportMarkdown = "markdown"
+	exportHTML     = "html"
+)
+
+// reportMeta identifies what an exported report is about.
+type reportMeta struct {
+	SessionID      string
+	SessionURL     string
+	Kind           string
+	Namespace      string
+	Name           string
+	LocalCluster   string
+	KomodorCluster string
+	Context        string
+	GeneratedAt    time.Time
+}
+
+func newReportMeta(config *Config, sessionID string) reportMeta {
+	return reportMeta{
+		SessionID:      sessionID,
+		SessionURL:     sessionWebURL(config, sessionID),
+		Kind:           config.Kind,
+		Namespace:      config.Namespace,
+		Name:           config.Name,
+		LocalCluster:   config.LocalClusterName,
+		KomodorCluster: config.KomodorClusterName,
+		Context:        config.Context,
+		GeneratedAt:    time.Now(),
+	}
+}
+
+func (r reportMeta) Resource() string {
+	if r.Kind == "" && r.Name == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s %s/%s", r.Kind, r.Namespace, r.Name)
+}
+
+func addExportFlags(cmd *cobra.Command) {
+	cmd.Flags().String("export", "", "Export the finished RCA as markdown or html")
+	cmd.Flags().String("export-file", "", "File to export to (default: ~/.k9s-komodor-rca/exports/rca-<session-id>.<ext>, - for stdout)")
+}
+
+// sessionWebURL links to a session in the Komodor web app. Komodor does not
+// document these links, so there is none unless --web-url says how to build
+// it: "{session}" in it is replaced by the session ID, which is appended as
+// a path segment otherwise.
+func sessionWebURL(config *Config, sessionID string) string {
+	if sessionID == "" || config.KomodorWebURL == "" {
+		return ""
+	}
+	id := url.PathEscape(sessionID)
+	if strings.Contains(config.KomodorWebURL, "{session}") {
+		return strings.ReplaceAll(config.KomodorWebURL, "{session}", id)
+	}
+	return strings.TrimRight(config.KomodorWebURL, "/") + "/" + id
+}
+
+// exportConfigured writes the report requested with --export, if any.
+func exportConfigured(config *Config, results *komodor.RCAPollResponse) {
+	if config.ExportFormat == "" {
+		return
+	}
+	path, err := exportReport(config.ExportFormat, config.ExportFile, newReportMeta(config, results.SessionID), results)
+	if err != nil {
+		logMessage("ERROR: Failed to export RCA report: %v", err)
+		config.TUI.DisplayError("Failed to export RCA report", err)
+		return
+	}
+	if path != "stdout" {
+		config.TUI.DisplayMessage(fmt.Sprintf("📄 RCA report exported to %s", path))
+	}
+}
+
+func exportExtension(format string) (string, error) {
+	switch format {
+	case exportMarkdown, "md":
+		return "md", nil
+	case exportHTML:
+		return "html", nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q (use markdown or html)", format)
+	}
+}
+
+// exportReport writes the report to path, or to the default exports
+// directory when path is empty. It returns where the report went.
+func exportReport(format, path string, meta reportMeta, results *komodor.RCAPollResponse) (string, error) {
+	ext, err := exportExtension(format)
+	if err != nil {
+		return "", err
+	}
+
+	if path == "-" {
+		return "stdout", writeReport(os.Stdout, ext, meta, results)
+	}
+
+	if path == "" {
+		if err := validateSessionID(meta.SessionID); err != nil {
+			return "", err
+		}
+		dir, err := appDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(dir, "exports", fmt.Sprintf("rca-%s.%s", meta.SessionID, ext))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer f.Close()
+
+	if err := writeReport(f, ext, meta, results); err != nil {
+		return "", fmt.Errorf("failed to write export file: %w", err)
+	}
+	logMessage("📄 Exported RCA report to %s", path)
+	return path, nil
+}
+
+func writeReport(w io.Writer, ext string, meta reportMeta, results *komodor.RCAPollResponse) error {
+	if ext == "html" {
+		return writeHTMLReport(w, meta, results)
+	}
+	return writeMarkdownReport(w, meta, results)
+}
+
+func writeMarkdownReport(w io.Writer, meta reportMeta, results *komodor.RCAPollResponse) error {
+	var s strings.Builder
+
+	title := "Root Cause Analysis"
+	if resource := meta.Resource(); resource != "" {
+		title += ": " + resource
+	}
+	fmt.Fprintf(&s, "# %s\n\n", title)
+
+	s.WriteString("| | |\n|---|---|\n")
+	if resource := meta.Resource(); resource != "" {
+		fmt.Fprintf(&s, "| Resource | `%s` |\n", resource)
+	}
+	if cluster := cmp.Or(meta.KomodorCluster, meta.LocalCluster); cluster != "" {
+		fmt.Fprintf(&s, "| Cluster | `%s` |\n", cluster)
+	}
+	if meta.Context != "" && meta.Context != meta.KomodorCluster {
+		fmt.Fprintf(&s, "| Context | `%s` |\n", meta.Context)
+	}
+	fmt.Fprintf(&s, "| Status | %s |\n", sessionState(results))
+	if meta.SessionURL != "" {
+		fmt.Fprintf(&s, "| Session | [%s](%s) |\n", meta.SessionID, meta.SessionURL)
+	} else {
+		fmt.Fprintf(&s, "| Session | `%s` |\n", meta.SessionID)
+	}
+	fmt.Fprintf(&s, "| Generated | %s |\n", meta.GeneratedAt.UTC().Format(time.RFC3339))
+
+	if results.ProblemShort != "" {
+		fmt.Fprintf(&s, "\n## Problem\n\n%s\n", results.ProblemShort)
+	}
+
+	if results.Recommendation != "" {
+		fmt.Fprintf(&s, "\n## Recommendation\n\n%s\n", results.Recommendation)
+	}
+
+	if len(results.WhatHappened) > 0 {
+		s.WriteString("\n## What Happened\n\n")
+		for i, event := range results.WhatHappened {
+			fmt.Fprintf(&s, "%d. %s\n", i+1, event)
+		}
+	}
+
+	if len(results.EvidenceCollection) > 0 {
+		s.WriteString("\n## Evidence\n")
+		for i, evidence := range results.EvidenceCollection {
+			fmt.Fprintf(&s, "\n### %d. %s\n\n", i+1, evidence.Query)
+			fence := "```"
+			for strings.Contains(evidence.Snippet, fence) {
+				fence += "`"
+			}
+			fmt.Fprintf(&s, "%s\n%s\n%s\n", fence, strings.TrimRight(evidence.Snippet, "\n"), fence)
+		}
+	}
+
+	if len(results.Operations) > 0 {
+		s.WriteString("\n## Operations\n\n")
+		for i, operation := range results.Operations {
+			fmt.Fprintf(&s, "%d. %s\n", i+1, operation)
+		}
+	}
+
+	_, err := io.WriteString(w, s.String())
+	return err
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Root Cause Analysis{{with .Meta.Resource}}: {{.}}{{end}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 960px; margin: 2rem auto; padding: 0 1rem; color: #1f2328; line-height: 1.5; }
+h1 { border-bottom: 1px solid #d0d7de; padding-bottom: .3rem; }
+h2 { margin-top: 2rem; border-bottom: 1px solid #d0d7de; padding-bottom: .2rem; }
+table.meta { border-collapse: collapse; }
+table.meta th { text-align: left; padding: .25rem 1rem .25rem 0; color: #59636e; font-weight: 600; }
+table.meta td { padding: .25rem 0; }
+code, pre { font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; font-size: 90%; }
+pre { background: #f6f8fa; border: 1px solid #d0d7de; border-radius: 6px; padding: .75rem; overflow-x: auto; white-space: pre-wrap; word-break: break-word; }
+.status { display: inline-block; padding: 0 .5rem; border-radius: 1rem; font-size: 85%; font-weight: 600; }
+.status-complete { background: #dafbe1; color: #1a7f37; }
+.status-failed { background: #ffebe9; color: #cf222e; }
+.status-stuck, .status-in-progress { background: #fff8c5; color: #9a6700; }
+.evidence h3 { font-size: 1rem; margin-bottom: .25rem; }
+</style>
+</head>
+<body>
+<h1>Root Cause Analysis{{with .Meta.Resource}}: <code>{{.}}</code>{{end}}</h1>
+<table class="meta">
+{{- with .Meta.Resource}}
+<tr><th>Resource</th><td><code>{{.}}</code></td></tr>
+{{- end}}
+{{- with .Cluster}}
+<tr><th>Cluster</th><td><code>{{.}}</code></td></tr>
+{{- end}}
+<tr><th>Status</th><td><span class="status status-{{.StatusClass}}">{{.Status}}</span></td></tr>
+<tr><th>Session</th><td>{{if .Meta.SessionURL}}<a href="{{.Meta.SessionURL}}">{{.Meta.SessionID}}</a>{{else}}<code>{{.Meta.SessionID}}</code>{{end}}</td></tr>
+<tr><th>Generated</th><td>{{.Generated}}</td></tr>
+</table>
+{{- with .Results.ProblemShort}}
+<h2>Problem</h2>
+<p>{{.}}</p>
+{{- end}}
+{{- with .Results.Recommendation}}
+<h2>Recommendation</h2>
+<p>{{.}}</p>
+{{- end}}
+{{- with .Results.WhatHappened}}
+<h2>What Happened</h2>
+<ol>
+{{- range .}}
+<li>{{.}}</li>
+{{- end}}
+</ol>
+{{- end}}
+{{- with .Results.EvidenceCollection}}
+<h2>Evidence</h2>
+{{- range $i, $e := .}}
+<div class="evidence">
+<h3>{{inc $i}}. {{$e.Query}}</h3>
+<pre>{{$e.Snippet}}</pre>
+</div>
+{{- end}}
+{{- end}}
+{{- with .Results.Operations}}
+<h2>Operations</h2>
+<ol>
+{{- range .}}
+<li>{{.}}</li>
+{{- end}}
+</ol>
+{{- end}}
+</body>
+</html>
+`))
+
+func writeHTMLReport(w io.Writer, meta reportMeta, results *komodor.RCAPollResponse) error {
+	status := sessionState(results)
+	return htmlReportTemplate.Execute(w, map[string]interface{}{
+		"Meta":        meta,
+		"Results":     results,
+		"Cluster":     cmp.Or(meta.KomodorCluster, meta.LocalCluster),
+		"Status":      status,
+		"StatusClass": strings.ReplaceAll(status, " ", "-"),
+		"Generated":   meta.GeneratedAt.UTC().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"k9s-rca/komodor"
+)
+
+type errorGuidance struct {
+	Summary string
+	Hint    string
+}
+
+// guidanceFor turns a Komodor API error into a short summary and a next step
+// the user can act on. It returns false for errors it knows nothing about.
+func guidanceFor(err error) (errorGuidance, bool) {
+	var apiErr *komodor.APIError
+	if !errors.As(err, &apiErr) {
+		return errorGuidance{}, false
+	}
+
+	message := strings.ToLower(apiErr.Message + " " + apiErr.Code)
+
+	switch {
+	case apiErr.IsUnauthorized():
+		return errorGuidance{
+			Summary: "API key invalid",
+			Hint:    "Check KOMODOR_API_KEY (or --api-key). Keys can be created in Komodor → Settings → API Keys.",
+		}, true
+	case apiErr.IsForbidden():
+		return errorGuidance{
+			Summary: "API key not allowed to run RCA",
+			Hint:    "Ask a Komodor admin to grant this API key access to Klaudia RCA for the cluster.",
+		}, true
+	case strings.Contains(message, "quota") || apiErr.StatusCode == http.StatusPaymentRequired:
+		return errorGuidance{
+			Summary: "RCA quota exceeded",
+			Hint:    "Your account has used its RCA allowance. Wait for it to reset or contact Komodor support.",
+		}, true
+	case apiErr.IsRateLimited():
+		return errorGuidance{
+			Summary: "Rate limited by Komodor",
+			Hint:    "Too many requests in a short time. Wait a moment and try again.",
+		}, true
+	case strings.Contains(message, "cluster") && (apiErr.IsNotFound() || apiErr.StatusCode == http.StatusBadRequest):
+		return errorGuidance{
+			Summary: "Cluster not found in Komodor",
+			Hint:    `Map this context to the cluster name shown in Komodor: k9s-rca clusters map "<context>" <komodor-cluster>`,
+		}, true
+	case apiErr.IsNotFound():
+		return errorGuidance{
+			Summary: "Not found in Komodor",
+			Hint:    "The session or resource does not exist, or is not visible to this API key.",
+		}, true
+	case apiErr.StatusCode == http.StatusBadRequest:
+		return errorGuidance{
+			Summary: "Request rejected by Komodor",
+			Hint:    "Check that the resource kind, namespace and name are valid for RCA.",
+		}, true
+	case apiErr.StatusCode >= 500:
+		return errorGuidance{
+			Summary: "Komodor API unavailable",
+			Hint:    "The service returned a server error. Try again shortly; include the request ID if you contact support.",
+		}, true
+	}
+
+	return errorGuidance{}, false
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"k9s-rca/komodor"
+)
+
+// HistoryEntry is one RCA session as stored on disk, one JSON file per
+// session under ~/.k9s-komodor-rca/history.
+type HistoryEntry struct {
+	SessionID      string                 `json:"sessionId"`
+	Kind           string                 `json:"kind,omitempty"`
+	Namespace      string                 `json:"namespace,omitempty"`
+	Name           string                 `json:"name,omitempty"`
+	LocalCluster   string                 `json:"localCluster,omitempty"`
+	KomodorCluster string                 `json:"komodorCluster,omitempty"`
+	Context        string                 `json:"context,omitempty"`
+	TriggeredAt    time.Time              `json:"triggeredAt"`
+	UpdatedAt      time.Time              `json:"updatedAt"`
+	FinishedAt     *time.Time             `json:"finishedAt,omitempty"`
+	Status         string                 `json:"status"`
+	ProblemShort   string                 `json:"problemShort,omitempty"`
+	RawData        map[string]interface{} `json:"rawData,omitempty"`
+	Chat           []ChatMessage          `json:"chat,omitempty"`
+}
+
+// ChatMessage is one turn of a follow-up conversation about a session.
+type ChatMessage struct {
+	Role    string    `json:"role"`
+	Content string    `json:"content"`
+	At      time.Time `json:"at"`
+	// Interrupted marks an answer that stopped before it was complete.
+	Interrupted bool `json:"interrupted,omitempty"`
+}
+
+const (
+	chatRoleUser      = "user"
+	chatRoleAssistant = "assistant"
+)
+
+// Results rebuilds the RCA response from the stored raw payload.
+func (e *HistoryEntry) Results() (*komodor.RCAPollResponse, error) {
+	results := &komodor.RCAPollResponse{SessionID: e.SessionID}
+	if e.RawData == nil {
+		return results, nil
+	}
+
+	data, err := json.Marshal(e.RawData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal stored payload: %w", err)
+	}
+	if err := json.Unmarshal(data, results); err != nil {
+		return nil, fmt.Errorf("failed to parse stored payload: %w", err)
+	}
+	results.RawData = e.RawData
+	if results.SessionID == "" {
+		results.SessionID = e.SessionID
+	}
+	return results, nil
+}
+
+func (e *HistoryEntry) Resource() string {
+	if e.Kind == "" && e.Name == "" {
+		return "(unknown resource)"
+	}
+	return fmt.Sprintf("%s %s/%s", e.Kind, e.Namespace, e.Name)
+}
+
+// writeFileAtomic writes data to a temp file next to path and renames it
+// into place, so a crash never leaves a truncated file behind. Every write
+// gets its own temp file, so concurrent writers cannot clobber each other's.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, perm)
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func appDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".k9s-komodor-rca"), nil
+}
+
+func historyDir() (string, error) {
+	dir, err := appDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history"), nil
+}
+
+// validateSessionID rejects IDs that cannot safely be part of a file name.
+func validateSessionID(sessionID string) error {
+	if sessionID == "" || strings.ContainsAny(sessionID, `/\`) || sessionID == "." || sessionID == ".." {
+		return fmt.Errorf("invalid session ID %q", sessionID)
+	}
+	return nil
+}
+
+func historyPath(sessionID string) (string, error) {
+	if err := validateSessionID(sessionID); err != nil {
+		return "", err
+	}
+	dir, err := historyDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, sessionID+".json"), nil
+}
+
+func loadHistoryEntry(sessionID string) (*HistoryEntry, error) {
+	path, err := historyPath(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry HistoryEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse history entry %s: %w", sessionID, err)
+	}
+	return &entry, nil
+}
+
+func saveHistoryEntry(entry *HistoryEntry) error {
+	path, err := historyPath(entry.SessionID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write history entry: %w", err)
+	}
+	return nil
+}
+
+// loadHistory returns every stored session, newest first. Unreadable entries
+// are skipped.
+func loadHistory() ([]*HistoryEntry, error) {
+	dir, err := historyDir()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read history directory: %w", err)
+	}
+
+	var entries []*HistoryEntry
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		entry, err := loadHistoryEntry(strings.TrimSuffix(file.Name(), ".json"))
+		if err != nil {
+			logMessage("⚠️  Skipping history entry %s: %v", file.Name(), err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].TriggeredAt.After(entries[j].TriggeredAt)
+	})
+	return entries, nil
+}
+
+func recordTriggeredSession(config *Config, sessionID string) {
+	now := time.Now()
+	entry := &HistoryEntry{
+		SessionID:      sessionID,
+		Kind:           config.Kind,
+		Namespace:      config.Namespace,
+		Name:           config.Name,
+		LocalCluster:   config.LocalClusterName,
+		KomodorCluster: config.KomodorClusterName,
+		Context:        config.Context,
+		TriggeredAt:    now,
+		UpdatedAt:      now,
+		Status:         "in progress",
+	}
+
+	if err := saveHistoryEntry(entry); err != nil {
+		logMessage("⚠️  Could not record session %s in history: %v", sessionID, err)
+		return
+	}
+	logMessage("💾 Recorded session %s in history", sessionID)
+	recordActiveSession(config, sessionID)
+}
+
+// recordSessionResults stores the latest known state of a session. Sessions
+// that were not triggered from this machine get a minimal entry.
+func recordSessionResults(sessionID string, results *komodor.RCAPollResponse) {
+	if results == nil || results.RawData == nil {
+		return
+	}
+
+	entry, err := loadHistoryEntry(sessionID)
+	if err != nil {
+		entry = &HistoryEntry{SessionID: sessionID, TriggeredAt: time.Now()}
+	}
+
+	now := time.Now()
+	entry.UpdatedAt = now
+	// A session the user stopped or left running keeps saying so until it
+	// reaches a final state.
+	if state := sessionState(results); state != "in progress" || (entry.Status != "cancelled" && entry.Status != "detached") {
+		entry.Status = state
+	}
+	entry.ProblemShort = results.ProblemShort
+	entry.RawData = results.RawData
+	if (results.IsComplete || results.IsFailed) && entry.FinishedAt == nil {
+		entry.FinishedAt = &now
+		markActiveSessionFinished(sessionID, false)
+	}
+
+	if err := saveHistoryEntry(entry); err != nil {
+		logMessage("⚠️  Could not update history for session %s: %v", sessionID, err)
+	}
+}
+
+// recordSessionOutcome notes what the user did with an unfinished session:
+// "cancelled" when it was stopped on the server, "detached" when it was left
+// running in the background.
+func recordSessionOutcome(sessionID, status string) {
+	entry, err := loadHistoryEntry(sessionID)
+	if err != nil {
+		entry = &HistoryEntry{SessionID: sessionID, TriggeredAt: time.Now()}
+	}
+
+	now := time.Now()
+	entry.Status = status
+	entry.UpdatedAt = now
+	if status == "cancelled" {
+		if entry.FinishedAt == nil {
+			entry.FinishedAt = &now
+		}
+		markActiveSessionFinished(sessionID, true)
+	}
+	if err := saveHistoryEntry(entry); err != nil {
+		logMessage("⚠️  Could not record %s session %s in history: %v", status, sessionID, err)
+	}
+}
+
+// recordChatMessage appends a follow-up question or answer to the session's
+// history entry.
+func recordChatMessage(sessionID string, message ChatMessage) {
+	entry, err := loadHistoryEntry(sessionID)
+	if err != nil {
+		entry = &HistoryEntry{SessionID: sessionID, TriggeredAt: time.Now(), Status: "complete"}
+	}
+
+	entry.Chat = append(entry.Chat, message)
+	entry.UpdatedAt = time.Now()
+	if err := saveHistoryEntry(entry); err != nil {
+		logMessage("⚠️  Could not save chat for session %s: %v", sessionID, err)
+	}
+}
+
+// applyHistoryResource fills in the resource a stored session was triggered
+// for, so reports and re-runs know what they are about.
+func applyHistoryResource(config *Config, entry *HistoryEntry) {
+	config.Kind = entry.Kind
+	config.Namespace = entry.Namespace
+	config.Name = entry.Name
+	config.LocalClusterName = entry.LocalCluster
+	config.KomodorClusterName = entry.KomodorCluster
+	config.Context = entry.Context
+}
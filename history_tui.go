@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type historyItem struct {
+	entry *HistoryEntry
+}
+
+func (i historyItem) Title() string {
+	return fmt.Sprintf("%s %s", statusIcon(i.entry.Status), i.entry.Resource())
+}
+
+func (i historyItem) Description() string {
+	parts := []string{i.entry.TriggeredAt.Local().Format("2006-01-02 15:04")}
+	if cluster := i.entry.clusterLabel(); cluster != "" {
+		parts = append(parts, cluster)
+	}
+	parts = append(parts, i.entry.Status)
+	if i.entry.ProblemShort != "" {
+		parts = append(parts, i.entry.ProblemShort)
+	}
+	return strings.Join(parts, " • ")
+}
+
+func (i historyItem) FilterValue() string {
+	return strings.Join([]string{
+		i.entry.Kind,
+		i.entry.Namespace,
+		i.entry.Name,
+		i.entry.LocalCluster,
+		i.entry.KomodorCluster,
+		i.entry.Status,
+		i.entry.SessionID,
+		i.entry.ProblemShort,
+	}, " ")
+}
+
+func (e *HistoryEntry) clusterLabel() string {
+	if e.KomodorCluster != "" {
+		return e.KomodorCluster
+	}
+	return e.LocalCluster
+}
+
+func statusIcon(status string) string {
+	switch status {
+	case "complete":
+		return "✅"
+	case "failed":
+		return "❌"
+	case "stuck":
+		return "⚠️ "
+	case "cancelled":
+		return "🛑"
+	case "detached":
+		return "🔄"
+	default:
+		return "⏳"
+	}
+}
+
+type historyModel struct {
+	list        list.Model
+	selected    *HistoryEntry
+	interrupted bool
+}
+
+func newHistoryModel(entries []*HistoryEntry) historyModel {
+	items := make([]list.Item, len(entries))
+	for i, entry := range entries {
+		items[i] = historyItem{entry: entry}
+	}
+
+	l := list.New(items, list.NewDefaultDelegate(), 0, 0)
+	l.Title = "RCA History"
+	l.Styles.Title = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("86")).
+		Background(lipgloss.Color("235")).
+		Padding(0, 1)
+	l.SetStatusBarItemName("session", "sessions")
+
+	return historyModel{list: l}
+}
+
+func (m historyModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m historyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetSize(msg.Width, msg.Height)
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		switch msg.String() {
+		case "ctrl+c":
+			m.interrupted = true
+			return m, tea.Quit
+		case "q":
+			return m, tea.Quit
+		case "enter":
+			if item, ok := m.list.SelectedItem().(historyItem); ok {
+				m.selected = item.entry
+				return m, tea.Quit
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m historyModel) View() string {
+	return m.list.View()
+}
+
+// selectHistoryEntry shows the history browser and returns the chosen entry,
+// or nil if the user quit. The cursor starts on the entry of selectedID, so
+// it stays where it was between runs even if the list was filtered.
+func selectHistoryEntry(ctx context.Context, entries []*HistoryEntry, selectedID string) (*HistoryEntry, error) {
+	model := newHistoryModel(entries)
+	for i, entry := range entries {
+		if entry.SessionID == selectedID {
+			model.list.Select(i)
+			break
+		}
+	}
+
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithContext(ctx))
+	final, err := p.Run()
+	if cause := context.Cause(ctx); cause != nil {
+		return nil, cause
+	}
+	if err != nil {
+		if errors.Is(err, tea.ErrInterrupted) {
+			return nil, errInterrupted
+		}
+		return nil, fmt.Errorf("error running history browser: %w", err)
+	}
+
+	finalModel := final.(historyModel)
+	if finalModel.interrupted {
+		return nil, errInterrupted
+	}
+	return finalModel.selected, nil
+}
+
+func formatAge(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
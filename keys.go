@@ -0,0 +1,98 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// rcaKeyMap holds every binding of the RCA view. Bindings that do not apply
+// to the current state are disabled by rcaModel.keyMap, which hides them
+// from the help bar and makes key.Matches ignore them.
+type rcaKeyMap struct {
+	NextTab key.Binding
+	PrevTab key.Binding
+	JumpTab key.Binding
+	Up      key.Binding
+	Down    key.Binding
+	Page    key.Binding
+	Ends    key.Binding
+	Top     key.Binding
+	Bottom  key.Binding
+
+	Inspect     key.Binding
+	Ask         key.Binding
+	Exit        key.Binding
+	Retrigger   key.Binding
+	KeepWaiting key.Binding
+	Rerun       key.Binding
+
+	StopSession key.Binding
+	Detach      key.Binding
+	Stay        key.Binding
+
+	CopyID             key.Binding
+	CopyProblem        key.Binding
+	CopyRecommendation key.Binding
+	CopyReport         key.Binding
+	Link               key.Binding
+	ExportMarkdown     key.Binding
+	ExportHTML         key.Binding
+
+	Help key.Binding
+	Quit key.Binding
+}
+
+func newRCAKeyMap() rcaKeyMap {
+	return rcaKeyMap{
+		NextTab: key.NewBinding(key.WithKeys("tab", "right", "l"), key.WithHelp("tab/→", "next tab")),
+		PrevTab: key.NewBinding(key.WithKeys("shift+tab", "left", "h"), key.WithHelp("shift+tab/←", "prev tab")),
+		JumpTab: key.NewBinding(key.WithKeys("1", "2", "3", "4", "5", "6", "7"), key.WithHelp("1-7", "jump to tab")),
+		Up:      key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+		Down:    key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+		// Paging itself is handled by the viewport; this is for the help bar.
+		Page:   key.NewBinding(key.WithKeys("pgup", "pgdown"), key.WithHelp("pgup/pgdn", "page")),
+		Ends:   key.NewBinding(key.WithKeys("home", "end"), key.WithHelp("home/end", "top/bottom")),
+		Top:    key.NewBinding(key.WithKeys("home", "g")),
+		Bottom: key.NewBinding(key.WithKeys("end", "G")),
+
+		Inspect:     key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "inspect")),
+		Ask:         key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "ask a question")),
+		Exit:        key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "exit")),
+		Retrigger:   key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "re-trigger")),
+		KeepWaiting: key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "keep waiting")),
+		Rerun:       key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "re-run & compare")),
+
+		StopSession: key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "stop analysis on server")),
+		Detach:      key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "keep running in background")),
+		Stay:        key.NewBinding(key.WithKeys("esc", "n"), key.WithHelp("esc", "cancel")),
+
+		CopyID:             key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "copy session ID")),
+		CopyProblem:        key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "copy problem")),
+		CopyRecommendation: key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "copy recommendation")),
+		CopyReport:         key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy report")),
+		Link:               key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "Komodor link")),
+		ExportMarkdown:     key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "export markdown")),
+		ExportHTML:         key.NewBinding(key.WithKeys("E"), key.WithHelp("E", "export html")),
+
+		Help: key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "more keys")),
+		Quit: key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+	}
+}
+
+// quitPrompt keeps only the answers to the quit prompt, plus ctrl+c.
+func (k rcaKeyMap) quitPrompt() rcaKeyMap {
+	quit := key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("ctrl+c", "quit"))
+	return rcaKeyMap{StopSession: k.StopSession, Detach: k.Detach, Stay: k.Stay, Quit: quit}
+}
+
+func (k rcaKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.StopSession, k.Detach, k.Stay, k.Retrigger, k.KeepWaiting, k.Rerun, k.Inspect, k.Ask, k.Exit, k.NextTab, k.CopyReport, k.Link, k.Help, k.Quit}
+}
+
+func (k rcaKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.NextTab, k.PrevTab, k.JumpTab, k.Up, k.Down, k.Page, k.Ends},
+		{k.Inspect, k.Ask, k.Exit, k.Retrigger, k.KeepWaiting, k.Rerun, k.ExportMarkdown, k.ExportHTML},
+		{k.CopyID, k.CopyProblem, k.CopyRecommendation, k.CopyReport, k.Link},
+		{k.StopSession, k.Detach, k.Stay, k.Help, k.Quit},
+	}
+}
@@ -0,0 +1,165 @@
+package komodor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrFollowUpUnsupported is returned by AskFollowUp when the API has no
+// follow-up endpoint for the session.
+var ErrFollowUpUnsupported = errors.New("follow-up questions are not supported")
+
+// FollowUpRequest is the body of a follow-up question.
+type FollowUpRequest struct {
+	Question string `json:"question"`
+}
+
+// FollowUpAnswer is the answer to a follow-up question. It is the whole
+// response body for application/json, and the data of every "message"
+// event, holding the next piece of the answer, for text/event-stream.
+// Event data that is not a JSON object is taken as plain answer text.
+type FollowUpAnswer struct {
+	Answer string `json:"answer"`
+}
+
+// AskFollowUp asks a question about a finished RCA session. When the API
+// streams the answer, as server-sent events or chunked text/plain, onChunk
+// is called with each piece as it arrives. The answer received so far is
+// returned even on error; onChunk may be nil.
+//
+// A server without the follow-up endpoint answers 404 or 405, which is
+// reported as ErrFollowUpUnsupported.
+func (c *Client) AskFollowUp(ctx context.Context, sessionID, question string, onChunk func(string)) (string, error) {
+	payload, err := json.Marshal(FollowUpRequest{Question: question})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.chatTimeout)
+	defer cancel()
+
+	path := "/api/v2/klaudia/rca/sessions/" + url.PathEscape(sessionID) + "/follow-up"
+	resp, err := c.send(ctx, http.MethodPost, path, payload, "text/event-stream, application/json;q=0.9, text/plain;q=0.8")
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && (apiErr.IsNotFound() || apiErr.StatusCode == http.StatusMethodNotAllowed) {
+			return "", fmt.Errorf("%w: %v", ErrFollowUpUnsupported, err)
+		}
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if onChunk == nil {
+		onChunk = func(string) {}
+	}
+
+	var answer strings.Builder
+	emit := func(s string) {
+		if s != "" {
+			answer.WriteString(s)
+			onChunk(s)
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/event-stream":
+		err = readEvents(resp.Body, func(event sseEvent) error {
+			switch event.Event {
+			case "error":
+				return fmt.Errorf("follow-up failed: %s", event.Data)
+			case "done":
+				return errStopStream
+			case "", "message":
+				text, err := decodeFollowUpAnswer([]byte(event.Data))
+				if err != nil {
+					return err
+				}
+				emit(text)
+				return nil
+			default:
+				return fmt.Errorf("unexpected follow-up event %q", event.Event)
+			}
+		})
+
+	case "application/json":
+		var body []byte
+		body, err = io.ReadAll(resp.Body)
+		if err == nil {
+			var text string
+			text, err = decodeFollowUpAnswer(body)
+			emit(text)
+		}
+
+	case "text/plain":
+		err = readText(resp.Body, emit)
+
+	default:
+		err = fmt.Errorf("unexpected content type %q", mediaType)
+	}
+
+	if err != nil {
+		return answer.String(), fmt.Errorf("failed to read follow-up answer: %w", err)
+	}
+	return answer.String(), nil
+}
+
+// decodeFollowUpAnswer returns the answer of a FollowUpAnswer object. Data
+// that is not a JSON object is a plain-text piece of the answer and is
+// returned as is; an object without an answer is an error, so that a changed
+// API fails loudly instead of yielding empty answers.
+func decodeFollowUpAnswer(data []byte) (string, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' || !json.Valid(trimmed) {
+		return string(data), nil
+	}
+
+	var answer struct {
+		Answer *string `json:"answer"`
+	}
+	if err := json.Unmarshal(trimmed, &answer); err != nil || answer.Answer == nil {
+		return "", fmt.Errorf("unexpected follow-up payload: %.200s", data)
+	}
+	return *answer.Answer, nil
+}
+
+// readText passes a text body to emit as it arrives, holding back the bytes
+// of a rune that is split across reads.
+func readText(r io.Reader, emit func(string)) error {
+	buf := make([]byte, 4096)
+	var pending []byte
+	for {
+		n, readErr := r.Read(buf)
+		pending = append(pending, buf[:n]...)
+
+		// Only the last rune can be incomplete.
+		complete := len(pending)
+		for i := len(pending) - 1; i >= 0 && i >= len(pending)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(pending[i]) {
+				if !utf8.FullRune(pending[i:]) {
+					complete = i
+				}
+				break
+			}
+		}
+		emit(string(pending[:complete]))
+		pending = append(pending[:0], pending[complete:]...)
+
+		if readErr != nil {
+			emit(string(pending))
+			if errors.Is(readErr, io.EOF) {
+				return nil
+			}
+			return readErr
+		}
+	}
+}
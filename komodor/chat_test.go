@@ -0,0 +1,116 @@
+package komodor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDecodeFollowUpAnswer(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{name: "answer object", data: `{"answer":"hello"}`, want: "hello"},
+		{name: "extra fields are ignored", data: `{"answer":"x","id":"1"}`, want: "x"},
+		{name: "empty answer", data: `{"answer":""}`, want: ""},
+		{name: "plain text", data: "Hello there", want: "Hello there"},
+		{name: "plain text keeps leading space", data: " world", want: " world"},
+		{name: "JSON scalar is text", data: "42", want: "42"},
+		{name: "text starting with a brace", data: "{not json", want: "{not json"},
+		{name: "object without answer", data: `{"text":"hello"}`, wantErr: true},
+		{name: "answer of the wrong type", data: `{"answer":3}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeFollowUpAnswer([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeFollowUpAnswer(%q) error = %v, wantErr %v", tt.data, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("decodeFollowUpAnswer(%q) = %q, want %q", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAskFollowUp(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+		wantChunks  int
+	}{
+		{
+			name:        "JSON events with extra fields",
+			contentType: "text/event-stream",
+			body:        "data: {\"answer\":\"Hel\",\"id\":\"1\"}\n\ndata: {\"answer\":\"lo\",\"id\":\"2\"}\n\nevent: done\ndata:\n\n",
+			want:        "Hello",
+			wantChunks:  2,
+		},
+		{
+			name:        "plain-text events",
+			contentType: "text/event-stream",
+			body:        "data: Hello\n\ndata:  world\n\nevent: done\ndata:\n\n",
+			want:        "Hello world",
+			wantChunks:  2,
+		},
+		{
+			name:        "JSON body with extra fields",
+			contentType: "application/json",
+			body:        `{"answer":"Hello","sources":[]}`,
+			want:        "Hello",
+			wantChunks:  1,
+		},
+		{
+			name:        "text body",
+			contentType: "text/plain; charset=utf-8",
+			body:        "Hello",
+			want:        "Hello",
+			wantChunks:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			chunks := 0
+			got, err := NewClient(server.URL, "key").AskFollowUp(context.Background(), "s1", "why?", func(string) { chunks++ })
+			if err != nil {
+				t.Fatalf("AskFollowUp() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("AskFollowUp() = %q, want %q", got, tt.want)
+			}
+			if chunks != tt.wantChunks {
+				t.Errorf("AskFollowUp() emitted %d chunks, want %d", chunks, tt.wantChunks)
+			}
+		})
+	}
+}
+
+func TestAskFollowUpUnsupported(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusMethodNotAllowed} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+
+		_, err := NewClient(server.URL, "key").AskFollowUp(context.Background(), "s1", "why?", nil)
+		if !errors.Is(err, ErrFollowUpUnsupported) {
+			t.Errorf("AskFollowUp() with status %d error = %v, want ErrFollowUpUnsupported", status, err)
+		}
+		server.Close()
+	}
+}
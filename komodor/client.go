@@ -0,0 +1,243 @@
+// Package komodor is a small client for the Komodor RCA API.
+package komodor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the Komodor API used when NewClient gets no base URL.
+	DefaultBaseURL = "https://api.komodor.com"
+	// DefaultUserAgent is sent unless WithUserAgent sets another one.
+	DefaultUserAgent = "k9s-rca"
+)
+
+// Client talks to the Komodor API. The zero value is not usable; build one
+// with NewClient.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	userAgent  string
+	httpClient *http.Client
+
+	createTimeout time.Duration
+	fetchTimeout  time.Duration
+	listTimeout   time.Duration
+	cancelTimeout time.Duration
+	chatTimeout   time.Duration
+
+	streamIdleTimeout time.Duration
+}
+
+// Option configures a Client in NewClient.
+type Option func(*Client)
+
+// WithHTTPClient replaces the underlying http.Client.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTransport replaces the underlying http.Client with a new one that
+// uses transport. It overrides an earlier WithHTTPClient.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.httpClient = &http.Client{Transport: transport}
+	}
+}
+
+// WithStreamIdleTimeout ends a session stream that has sent nothing, not
+// even a heartbeat, for d. Zero, the default, waits as long as the context
+// allows.
+func WithStreamIdleTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.streamIdleTimeout = d
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// NewClient returns a client for the API at baseURL, DefaultBaseURL if
+// empty, authenticated with apiKey.
+func NewClient(baseURL, apiKey string, opts ...Option) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	c := &Client{
+		baseURL:       baseURL,
+		apiKey:        apiKey,
+		userAgent:     DefaultUserAgent,
+		httpClient:    &http.Client{},
+		createTimeout: 30 * time.Second,
+		fetchTimeout:  360 * time.Second,
+		listTimeout:   30 * time.Second,
+		cancelTimeout: 30 * time.Second,
+		chatTimeout:   5 * time.Minute,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// BaseURL is the API URL the client sends requests to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// CreateSession starts a new RCA session for the given resource.
+func (c *Client) CreateSession(ctx context.Context, session *RCASession) (*RCAResponse, error) {
+	jsonData, err := json.Marshal(session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	body, err := c.do(ctx, c.createTimeout, http.MethodPost, "/api/v2/klaudia/rca/sessions", jsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	var rcaResp RCAResponse
+	if err := json.Unmarshal(body, &rcaResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &rcaResp, nil
+}
+
+// GetSession fetches the current state of an RCA session. The decoded JSON
+// is also kept in RawData.
+func (c *Client) GetSession(ctx context.Context, sessionID string) (*RCAPollResponse, error) {
+	body, err := c.do(ctx, c.fetchTimeout, http.MethodGet, "/api/v2/klaudia/rca/sessions/"+url.PathEscape(sessionID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSession(body)
+}
+
+// decodeSession parses a session payload, keeping the decoded JSON in
+// RawData.
+func decodeSession(body []byte) (*RCAPollResponse, error) {
+	var rawData map[string]interface{}
+	if err := json.Unmarshal(body, &rawData); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal raw response: %w", err)
+	}
+
+	var pollResp RCAPollResponse
+	if err := json.Unmarshal(body, &pollResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	pollResp.RawData = rawData
+
+	return &pollResp, nil
+}
+
+// ErrCancelUnsupported is returned by CancelSession when the API offers no
+// way to stop the session.
+var ErrCancelUnsupported = errors.New("stopping sessions is not supported")
+
+// CancelSession stops a running RCA session on the server. The cancel
+// endpoint is not part of Komodor's documented API; a server without it
+// answers 404 or 405, which is reported as ErrCancelUnsupported.
+func (c *Client) CancelSession(ctx context.Context, sessionID string) error {
+	_, err := c.do(ctx, c.cancelTimeout, http.MethodPost, "/api/v2/klaudia/rca/sessions/"+url.PathEscape(sessionID)+"/cancel", nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && (apiErr.IsNotFound() || apiErr.StatusCode == http.StatusMethodNotAllowed) {
+		return fmt.Errorf("%w: %v", ErrCancelUnsupported, err)
+	}
+	return err
+}
+
+// ListClusters returns every cluster visible to the API key.
+func (c *Client) ListClusters(ctx context.Context) ([]Cluster, error) {
+	body, err := c.do(ctx, c.listTimeout, http.MethodGet, "/api/v2/clusters", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var clustersResp ClustersResponse
+	if err := json.Unmarshal(body, &clustersResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return clustersResp.Data.Clusters, nil
+}
+
+func (c *Client) do(ctx context.Context, timeout time.Duration, method, path string, payload []byte) ([]byte, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	resp, err := c.send(ctx, method, path, payload, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return body, nil
+}
+
+// send performs a request and returns the response with its body unread,
+// so streaming callers can consume it as it arrives. Non-2xx responses are
+// turned into an *APIError.
+func (c *Client) send(ctx context.Context, method, path string, payload []byte, accept string) (*http.Response, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("Accept", accept)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		return nil, newAPIError(resp, body)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,134 @@
+package komodor
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// APIError is returned for any non-2xx response from the Komodor API. Use
+// errors.As to inspect it.
+type APIError struct {
+	StatusCode int
+	// Code is the machine-readable error code, if the body had one.
+	Code string
+	// Message is the human-readable reason, falling back to the status text.
+	Message string
+	// RequestID identifies the request for Komodor support.
+	RequestID string
+	// RetryAfter is the server's Retry-After hint, zero if absent.
+	RetryAfter time.Duration
+	// Body is the raw response body, kept for debugging.
+	Body string
+}
+
+func (e *APIError) Error() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "Komodor API error (HTTP %d", e.StatusCode)
+	if e.Code != "" {
+		fmt.Fprintf(&s, ", %s", e.Code)
+	}
+	s.WriteString("): ")
+	s.WriteString(e.Message)
+	if e.RequestID != "" {
+		fmt.Fprintf(&s, " [request ID: %s]", e.RequestID)
+	}
+	return s.String()
+}
+
+// IsUnauthorized reports a missing or invalid API key.
+func (e *APIError) IsUnauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized
+}
+
+// IsForbidden reports an API key that lacks the needed permissions.
+func (e *APIError) IsForbidden() bool {
+	return e.StatusCode == http.StatusForbidden
+}
+
+// IsNotFound reports an unknown session or endpoint.
+func (e *APIError) IsNotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// IsRateLimited reports that the API key sent too many requests.
+func (e *APIError) IsRateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// maxPlainMessageLength is how many characters of a plain-text body are kept
+// as the message.
+const maxPlainMessageLength = 200
+
+type errorBody struct {
+	Code      string          `json:"code"`
+	ErrorCode string          `json:"errorCode"`
+	Message   string          `json:"message"`
+	Detail    string          `json:"detail"`
+	RequestID string          `json:"requestId"`
+	Error     json.RawMessage `json:"error"`
+	Errors    []struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		RequestID:  firstHeader(resp.Header, "X-Request-Id", "X-Komodor-Request-Id", "X-Amzn-Requestid"),
+	}
+
+	var parsed errorBody
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		apiErr.Code = cmp.Or(parsed.Code, parsed.ErrorCode)
+		apiErr.Message = cmp.Or(parsed.Message, parsed.Detail)
+		if apiErr.RequestID == "" {
+			apiErr.RequestID = parsed.RequestID
+		}
+
+		// "error" is either a plain string or a nested object.
+		if len(parsed.Error) > 0 {
+			var text string
+			var nested struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			}
+			if json.Unmarshal(parsed.Error, &text) == nil {
+				apiErr.Message = cmp.Or(apiErr.Message, text)
+			} else if json.Unmarshal(parsed.Error, &nested) == nil {
+				apiErr.Code = cmp.Or(apiErr.Code, nested.Code)
+				apiErr.Message = cmp.Or(apiErr.Message, nested.Message)
+			}
+		}
+		if len(parsed.Errors) > 0 {
+			apiErr.Code = cmp.Or(apiErr.Code, parsed.Errors[0].Code)
+			apiErr.Message = cmp.Or(apiErr.Message, parsed.Errors[0].Message)
+		}
+	} else if text := strings.TrimSpace(string(body)); text != "" && !strings.HasPrefix(text, "<") {
+		if runes := []rune(text); len(runes) > maxPlainMessageLength {
+			text = string(runes[:maxPlainMessageLength]) + "…"
+		}
+		apiErr.Message = text
+	}
+
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+
+	return apiErr
+}
+
+func firstHeader(header http.Header, keys ...string) string {
+	for _, key := range keys {
+		if value := header.Get(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
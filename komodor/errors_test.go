@@ -0,0 +1,117 @@
+package komodor
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		header     http.Header
+		body       string
+		want       APIError
+		wantString string
+	}{
+		{
+			name:   "code and message",
+			status: http.StatusBadRequest,
+			body:   `{"code":"INVALID_KIND","message":"unknown kind Foo","requestId":"req-1"}`,
+			want:   APIError{Code: "INVALID_KIND", Message: "unknown kind Foo", RequestID: "req-1"},
+		},
+		{
+			name:   "errorCode and detail",
+			status: http.StatusForbidden,
+			body:   `{"errorCode":"NO_ACCESS","detail":"missing RCA permission"}`,
+			want:   APIError{Code: "NO_ACCESS", Message: "missing RCA permission"},
+		},
+		{
+			name:   "error as string",
+			status: http.StatusUnauthorized,
+			body:   `{"error":"invalid api key"}`,
+			want:   APIError{Message: "invalid api key"},
+		},
+		{
+			name:   "error as object",
+			status: http.StatusNotFound,
+			body:   `{"error":{"code":"NOT_FOUND","message":"session not found"}}`,
+			want:   APIError{Code: "NOT_FOUND", Message: "session not found"},
+		},
+		{
+			name:   "errors list",
+			status: http.StatusUnprocessableEntity,
+			body:   `{"errors":[{"code":"BAD_NAME","message":"name is required"},{"code":"BAD_NS"}]}`,
+			want:   APIError{Code: "BAD_NAME", Message: "name is required"},
+		},
+		{
+			name:   "plain text",
+			status: http.StatusBadGateway,
+			body:   "  upstream unavailable\n",
+			want:   APIError{Message: "upstream unavailable"},
+		},
+		{
+			name:   "long plain text is truncated",
+			status: http.StatusInternalServerError,
+			body:   strings.Repeat("x", 300),
+			want:   APIError{Message: strings.Repeat("x", maxPlainMessageLength) + "…"},
+		},
+		{
+			name:   "truncation keeps multi-byte characters whole",
+			status: http.StatusInternalServerError,
+			body:   strings.Repeat("é", 300),
+			want:   APIError{Message: strings.Repeat("é", maxPlainMessageLength) + "…"},
+		},
+		{
+			name:   "html falls back to status text",
+			status: http.StatusServiceUnavailable,
+			body:   "<html><body>down</body></html>",
+			want:   APIError{Message: "Service Unavailable"},
+		},
+		{
+			name:   "empty body",
+			status: http.StatusTooManyRequests,
+			header: http.Header{"Retry-After": {"3"}, "X-Request-Id": {"hdr-1"}},
+			want:   APIError{Message: "Too Many Requests", RetryAfter: 3 * time.Second, RequestID: "hdr-1"},
+		},
+		{
+			name:   "header request ID wins over body",
+			status: http.StatusBadRequest,
+			header: http.Header{"X-Komodor-Request-Id": {"hdr-2"}},
+			body:   `{"message":"bad","requestId":"body-2"}`,
+			want:   APIError{Message: "bad", RequestID: "hdr-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: tt.header}
+			if resp.Header == nil {
+				resp.Header = http.Header{}
+			}
+
+			got := newAPIError(resp, []byte(tt.body))
+			tt.want.StatusCode = tt.status
+			tt.want.Body = tt.body
+			if *got != tt.want {
+				t.Errorf("newAPIError() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIErrorString(t *testing.T) {
+	err := &APIError{StatusCode: 404, Code: "NOT_FOUND", Message: "session not found", RequestID: "req-1"}
+	want := "Komodor API error (HTTP 404, NOT_FOUND): session not found [request ID: req-1]"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	err = &APIError{StatusCode: 500, Message: "Internal Server Error"}
+	want = "Komodor API error (HTTP 500): Internal Server Error"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
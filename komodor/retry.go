@@ -0,0 +1,122 @@
+package komodor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy decides whether a failed call is worth repeating and how long
+// to wait before doing so.
+type RetryPolicy struct {
+	// MaxRetries is the number of consecutive failures tolerated before
+	// giving up.
+	MaxRetries int
+	// BaseDelay is the wait after the first failure; it doubles on every
+	// further attempt.
+	BaseDelay time.Duration
+	// MaxWait caps a single backoff, including one asked for by the server
+	// with Retry-After. Zero means no cap.
+	MaxWait time.Duration
+}
+
+// DefaultRetryPolicy is the policy used by the CLI unless overridden by
+// flags.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 72,
+		BaseDelay:  2 * time.Second,
+		MaxWait:    30 * time.Second,
+	}
+}
+
+// Backoff returns how long to wait before retry number attempt (starting at
+// 1). The delay grows exponentially with jitter, unless err carries a
+// Retry-After hint. Either way it is at most MaxWait.
+func (p RetryPolicy) Backoff(attempt int, err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		if p.MaxWait > 0 && apiErr.RetryAfter > p.MaxWait {
+			return p.MaxWait
+		}
+		return apiErr.RetryAfter
+	}
+
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < attempt && i < 32; i++ {
+		delay *= 2
+		if p.MaxWait > 0 && delay >= p.MaxWait {
+			break
+		}
+	}
+	if p.MaxWait > 0 && delay > p.MaxWait {
+		delay = p.MaxWait
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// Equal jitter: keep half of the delay and randomize the rest so
+	// concurrent pollers do not retry in lockstep.
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// IsRetryable reports whether err is transient: server errors, rate limiting
+// and network failures. Client errors such as a bad API key or an unknown
+// session are permanent.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusTooManyRequests,
+			apiErr.StatusCode == http.StatusRequestTimeout,
+			apiErr.StatusCode >= 500:
+			return true
+		default:
+			return false
+		}
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		if d := time.Until(when); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,105 @@
+package komodor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 5, BaseDelay: 2 * time.Second, MaxWait: 30 * time.Second}
+
+	tests := []struct {
+		name     string
+		policy   RetryPolicy
+		attempt  int
+		err      error
+		min, max time.Duration
+	}{
+		{"first attempt", policy, 1, errors.New("boom"), time.Second, 2 * time.Second},
+		{"attempt zero counts as first", policy, 0, nil, time.Second, 2 * time.Second},
+		{"doubles", policy, 3, nil, 4 * time.Second, 8 * time.Second},
+		{"capped at max wait", policy, 10, nil, 15 * time.Second, 30 * time.Second},
+		{"huge attempt does not overflow", policy, 1000, nil, 15 * time.Second, 30 * time.Second},
+		{"retry-after honored", policy, 1, &APIError{StatusCode: 429, RetryAfter: 7 * time.Second}, 7 * time.Second, 7 * time.Second},
+		{"retry-after capped", policy, 1, &APIError{StatusCode: 503, RetryAfter: time.Hour}, 30 * time.Second, 30 * time.Second},
+		{"wrapped retry-after", policy, 1, fmt.Errorf("poll: %w", &APIError{StatusCode: 429, RetryAfter: 5 * time.Second}), 5 * time.Second, 5 * time.Second},
+		{"retry-after without cap", RetryPolicy{BaseDelay: time.Second}, 1, &APIError{StatusCode: 429, RetryAfter: time.Hour}, time.Hour, time.Hour},
+		{"zero base delay", RetryPolicy{}, 3, nil, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 20 {
+				got := tt.policy.Backoff(tt.attempt, tt.err)
+				if got < tt.min || got > tt.max {
+					t.Fatalf("Backoff(%d) = %s, want between %s and %s", tt.attempt, got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value    string
+		min, max time.Duration
+	}{
+		{"", 0, 0},
+		{"0", 0, 0},
+		{"12", 12 * time.Second, 12 * time.Second},
+		{"-3", 0, 0},
+		{"soon", 0, 0},
+		{time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0, 0},
+		{time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), 58 * time.Second, time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value); got < tt.min || got > tt.max {
+				t.Errorf("parseRetryAfter(%q) = %s, want between %s and %s", tt.value, got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+var _ net.Error = timeoutError{}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"rate limited", &APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{"request timeout", &APIError{StatusCode: http.StatusRequestTimeout}, true},
+		{"server error", &APIError{StatusCode: http.StatusBadGateway}, true},
+		{"wrapped server error", fmt.Errorf("poll: %w", &APIError{StatusCode: 500}), true},
+		{"unauthorized", &APIError{StatusCode: http.StatusUnauthorized}, false},
+		{"not found", &APIError{StatusCode: http.StatusNotFound}, false},
+		{"cancelled", fmt.Errorf("failed to make request: %w", context.Canceled), false},
+		{"network timeout", fmt.Errorf("failed to make request: %w", timeoutError{}), true},
+		{"unexpected EOF", fmt.Errorf("failed to read response body: %w", io.ErrUnexpectedEOF), true},
+		{"other", errors.New("failed to unmarshal response"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %t, want %t", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package komodor
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+)
+
+// errStopStream ends readEvents early without reporting an error.
+var errStopStream = errors.New("stop stream")
+
+// sseEvent is one server-sent event.
+type sseEvent struct {
+	Event string
+	Data  string
+	ID    string
+}
+
+// readEvents parses a text/event-stream body and calls fn for every event.
+// It returns when the stream ends, fn returns an error, or reading fails.
+func readEvents(r io.Reader, fn func(sseEvent) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var event sseEvent
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 && event.Event == "" {
+			return nil
+		}
+		event.Data = strings.Join(data, "\n")
+		err := fn(event)
+		event, data = sseEvent{}, nil
+		return err
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if err := dispatch(); err != nil {
+				return stopped(err)
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			event.ID = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return stopped(dispatch())
+}
+
+func stopped(err error) error {
+	if errors.Is(err, errStopStream) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+package komodor
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadEvents(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []sseEvent
+	}{
+		{
+			name:  "single data event",
+			input: "data: hello\n\n",
+			want:  []sseEvent{{Data: "hello"}},
+		},
+		{
+			name:  "named event with id",
+			input: "event: update\nid: 7\ndata: {\"a\":1}\n\n",
+			want:  []sseEvent{{Event: "update", ID: "7", Data: `{"a":1}`}},
+		},
+		{
+			name:  "multi-line data is joined",
+			input: "data: first\ndata: second\n\n",
+			want:  []sseEvent{{Data: "first\nsecond"}},
+		},
+		{
+			name:  "comments and blank lines are skipped",
+			input: ": heartbeat\n\n\ndata: x\n\n: another\n\n",
+			want:  []sseEvent{{Data: "x"}},
+		},
+		{
+			name:  "value without space after colon",
+			input: "data:tight\n\n",
+			want:  []sseEvent{{Data: "tight"}},
+		},
+		{
+			name:  "only the first space is stripped",
+			input: "data:  indented\n\n",
+			want:  []sseEvent{{Data: " indented"}},
+		},
+		{
+			name:  "unknown fields are ignored",
+			input: "retry: 1000\nfoo: bar\ndata: y\n\n",
+			want:  []sseEvent{{Data: "y"}},
+		},
+		{
+			name:  "event without data",
+			input: "event: done\n\n",
+			want:  []sseEvent{{Event: "done"}},
+		},
+		{
+			name:  "last event without trailing blank line",
+			input: "data: a\n\ndata: b",
+			want:  []sseEvent{{Data: "a"}, {Data: "b"}},
+		},
+		{
+			name:  "CRLF line endings",
+			input: "data: crlf\r\n\r\n",
+			want:  []sseEvent{{Data: "crlf"}},
+		},
+		{
+			name:  "empty stream",
+			input: "",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []sseEvent
+			err := readEvents(strings.NewReader(tt.input), func(event sseEvent) error {
+				got = append(got, event)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("readEvents() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readEvents() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadEventsStops(t *testing.T) {
+	input := "data: 1\n\ndata: 2\n\ndata: 3\n\n"
+
+	var seen int
+	err := readEvents(strings.NewReader(input), func(event sseEvent) error {
+		seen++
+		if event.Data == "2" {
+			return errStopStream
+		}
+		return nil
+	})
+	if err != nil || seen != 2 {
+		t.Errorf("errStopStream: err = %v after %d events, want nil after 2", err, seen)
+	}
+
+	boom := errors.New("boom")
+	err = readEvents(strings.NewReader(input), func(sseEvent) error {
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("callback error: err = %v, want %v", err, boom)
+	}
+}
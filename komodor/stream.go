@@ -0,0 +1,131 @@
+package komodor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ErrStreamingUnsupported is returned by StreamSession when the API does not
+// offer a stream for the session. Callers should poll GetSession instead.
+var ErrStreamingUnsupported = errors.New("session streaming is not supported")
+
+// ErrStreamIdle is returned by StreamSession when the stream went silent for
+// longer than the WithStreamIdleTimeout setting.
+var ErrStreamIdle = errors.New("session stream went idle")
+
+// StreamSession follows an RCA session as server-sent events. fn is called
+// with a full snapshot of the session every time it changes, in the same
+// shape GetSession returns. StreamSession returns nil when the server ends
+// the stream, or the first error fn returns. The time fn takes does not
+// count towards the idle timeout.
+func (c *Client) StreamSession(ctx context.Context, sessionID string, fn func(*RCAPollResponse) error) error {
+	parent := ctx
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	idle := &idleTimer{timeout: c.streamIdleTimeout}
+	idle.start(func() { cancel(ErrStreamIdle) })
+	defer idle.stop()
+
+	path := "/api/v2/klaudia/rca/sessions/" + url.PathEscape(sessionID) + "/stream"
+	resp, err := c.send(ctx, http.MethodGet, path, nil, "text/event-stream")
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && streamingUnsupported(apiErr.StatusCode) {
+			return fmt.Errorf("%w: %v", ErrStreamingUnsupported, err)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/event-stream" {
+		return fmt.Errorf("%w: server replied with %q", ErrStreamingUnsupported, mediaType)
+	}
+
+	err = readEvents(idle.reader(resp.Body), func(event sseEvent) error {
+		switch event.Event {
+		case "error":
+			return fmt.Errorf("session stream failed: %s", event.Data)
+		case "", "message", "session", "update":
+		default:
+			// Heartbeats and event types we don't know about.
+			return nil
+		}
+		if event.Data == "" {
+			return nil
+		}
+
+		results, err := decodeSession([]byte(event.Data))
+		if err != nil {
+			return err
+		}
+		idle.stop()
+		defer idle.reset()
+		return fn(results)
+	})
+	switch {
+	case err == nil || parent.Err() != nil:
+		return err
+	case errors.Is(context.Cause(ctx), ErrStreamIdle):
+		return fmt.Errorf("%w: nothing received for %s", ErrStreamIdle, c.streamIdleTimeout)
+	default:
+		return fmt.Errorf("failed to read session stream: %w", err)
+	}
+}
+
+// idleTimer fires when a stream has been silent for timeout. A zero timeout
+// never fires.
+type idleTimer struct {
+	timeout time.Duration
+	timer   *time.Timer
+}
+
+func (t *idleTimer) start(fire func()) {
+	if t.timeout > 0 {
+		t.timer = time.AfterFunc(t.timeout, fire)
+	}
+}
+
+func (t *idleTimer) stop() {
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
+
+func (t *idleTimer) reset() {
+	if t.timer != nil {
+		t.timer.Reset(t.timeout)
+	}
+}
+
+// reader resets the timer whenever r delivers data.
+func (t *idleTimer) reader(r io.Reader) io.Reader {
+	return readerFunc(func(p []byte) (int, error) {
+		n, err := r.Read(p)
+		if n > 0 {
+			t.reset()
+		}
+		return n, err
+	})
+}
+
+type readerFunc func([]byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
+
+func streamingUnsupported(statusCode int) bool {
+	switch statusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotAcceptable, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package komodor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// streamServer serves handler as the stream of every session.
+func streamServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/stream") {
+			http.NotFound(w, r)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeEvent(w http.ResponseWriter, event string) {
+	fmt.Fprint(w, event)
+	w.(http.Flusher).Flush()
+}
+
+func TestStreamSession(t *testing.T) {
+	server := streamServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeEvent(w, ": heartbeat\n\n")
+		writeEvent(w, "data: {\"sessionId\":\"s1\",\"operations\":[\"a\"]}\n\n")
+		writeEvent(w, "event: ping\ndata: ignored\n\n")
+		writeEvent(w, "event: update\ndata: {\"sessionId\":\"s1\",\"isComplete\":true,\"extra\":1}\n\n")
+	})
+
+	var snapshots []*RCAPollResponse
+	err := NewClient(server.URL, "key").StreamSession(context.Background(), "s1", func(results *RCAPollResponse) error {
+		snapshots = append(snapshots, results)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamSession() error = %v", err)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("StreamSession() delivered %d snapshots, want 2", len(snapshots))
+	}
+	if snapshots[0].IsComplete || len(snapshots[0].Operations) != 1 {
+		t.Errorf("first snapshot = %+v, want one operation and not complete", snapshots[0])
+	}
+	if !snapshots[1].IsComplete || snapshots[1].RawData["extra"] != float64(1) {
+		t.Errorf("second snapshot = %+v, want complete with raw data", snapshots[1])
+	}
+}
+
+func TestStreamSessionUnsupported(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(w http.ResponseWriter, r *http.Request)
+	}{
+		{
+			name:    "not found",
+			handler: func(w http.ResponseWriter, r *http.Request) { http.NotFound(w, r) },
+		},
+		{
+			name: "not implemented",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotImplemented)
+			},
+		},
+		{
+			name: "not an event stream",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"sessionId":"s1"}`)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := streamServer(t, tt.handler)
+			err := NewClient(server.URL, "key").StreamSession(context.Background(), "s1", func(*RCAPollResponse) error {
+				t.Error("StreamSession() delivered a snapshot")
+				return nil
+			})
+			if !errors.Is(err, ErrStreamingUnsupported) {
+				t.Errorf("StreamSession() error = %v, want ErrStreamingUnsupported", err)
+			}
+		})
+	}
+}
+
+func TestStreamSessionErrorEvent(t *testing.T) {
+	server := streamServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeEvent(w, "event: error\ndata: analysis crashed\n\n")
+	})
+
+	err := NewClient(server.URL, "key").StreamSession(context.Background(), "s1", func(*RCAPollResponse) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "analysis crashed") {
+		t.Errorf("StreamSession() error = %v, want the error event", err)
+	}
+}
+
+func TestStreamSessionIdle(t *testing.T) {
+	const idleTimeout = 100 * time.Millisecond
+
+	t.Run("silent stream", func(t *testing.T) {
+		server := streamServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			writeEvent(w, "data: {\"sessionId\":\"s1\"}\n\n")
+			<-r.Context().Done()
+		})
+
+		snapshots := 0
+		start := time.Now()
+		err := NewClient(server.URL, "key", WithStreamIdleTimeout(idleTimeout)).StreamSession(context.Background(), "s1", func(*RCAPollResponse) error {
+			snapshots++
+			return nil
+		})
+		if !errors.Is(err, ErrStreamIdle) {
+			t.Fatalf("StreamSession() error = %v, want ErrStreamIdle", err)
+		}
+		if snapshots != 1 {
+			t.Errorf("StreamSession() delivered %d snapshots, want 1", snapshots)
+		}
+		if elapsed := time.Since(start); elapsed > 10*idleTimeout {
+			t.Errorf("StreamSession() took %s to notice the idle stream", elapsed)
+		}
+	})
+
+	t.Run("heartbeats keep the stream open", func(t *testing.T) {
+		server := streamServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			for range 6 {
+				writeEvent(w, ": heartbeat\n\n")
+				time.Sleep(idleTimeout / 3)
+			}
+			writeEvent(w, "data: {\"sessionId\":\"s1\",\"isComplete\":true}\n\n")
+		})
+
+		err := NewClient(server.URL, "key", WithStreamIdleTimeout(idleTimeout)).StreamSession(context.Background(), "s1", func(*RCAPollResponse) error { return nil })
+		if err != nil {
+			t.Errorf("StreamSession() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("time spent in fn does not count", func(t *testing.T) {
+		server := streamServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			writeEvent(w, "data: {\"sessionId\":\"s1\"}\n\n")
+			writeEvent(w, "data: {\"sessionId\":\"s1\",\"isComplete\":true}\n\n")
+		})
+
+		err := NewClient(server.URL, "key", WithStreamIdleTimeout(idleTimeout)).StreamSession(context.Background(), "s1", func(*RCAPollResponse) error {
+			time.Sleep(2 * idleTimeout)
+			return nil
+		})
+		if err != nil {
+			t.Errorf("StreamSession() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("cancelled by the caller", func(t *testing.T) {
+		server := streamServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			writeEvent(w, ": heartbeat\n\n")
+			<-r.Context().Done()
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), idleTimeout/2)
+		defer cancel()
+		err := NewClient(server.URL, "key", WithStreamIdleTimeout(idleTimeout)).StreamSession(ctx, "s1", func(*RCAPollResponse) error { return nil })
+		if err == nil || errors.Is(err, ErrStreamIdle) {
+			t.Errorf("StreamSession() error = %v, want the caller's cancellation", err)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package komodor
+
+// RCASession identifies the resource an RCA session is started for.
+type RCASession struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	// ClusterName is the cluster's name in Komodor, not in the kubeconfig.
+	ClusterName string `json:"clusterName"`
+}
+
+// RCAResponse is returned when a session is created.
+type RCAResponse struct {
+	SessionID string `json:"sessionId"`
+	Status    string `json:"status"`
+}
+
+// Evidence is one query the analysis ran and an excerpt of its result.
+type Evidence struct {
+	Query   string `json:"query"`
+	Snippet string `json:"snippet"`
+}
+
+// RCAPollResponse is the state of an RCA session. Fields fill in as the
+// analysis progresses.
+type RCAPollResponse struct {
+	SessionID string `json:"sessionId"`
+	// IsComplete is set once the analysis has produced its final result.
+	IsComplete bool `json:"isComplete"`
+	// IsFailed is set when the analysis ended without a result.
+	IsFailed bool `json:"isFailed"`
+	// IsStuck is set while the analysis is running but making no progress.
+	IsStuck bool `json:"isStuck"`
+	// ProblemShort is a one-line summary of the problem found.
+	ProblemShort string `json:"problemShort"`
+	// Recommendation is the suggested fix.
+	Recommendation string `json:"recommendation"`
+	// WhatHappened is the timeline of events that led to the problem.
+	WhatHappened []string `json:"whatHappened"`
+	// EvidenceCollection holds the queries behind the conclusion.
+	EvidenceCollection []Evidence `json:"evidenceCollection"`
+	// Operations lists the steps the analysis went through.
+	Operations []string `json:"operations"`
+	// RawData is the whole decoded payload, including fields not mapped
+	// above.
+	RawData map[string]interface{} `json:"-"`
+}
+
+// Cluster is a cluster connected to Komodor.
+type Cluster struct {
+	APIServerURL string `json:"apiServerUrl"`
+	// ClusterID is the UID of the cluster's kube-system namespace.
+	ClusterID string            `json:"clusterId"`
+	Name      string            `json:"name"`
+	Tags      map[string]string `json:"tags"`
+}
+
+// ClustersResponse is the payload of the cluster list endpoint.
+type ClustersResponse struct {
+	Data struct {
+		Clusters []Cluster `json:"clusters"`
+	} `json:"data"`
+}
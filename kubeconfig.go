@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// kubeRequestTimeout bounds a single request to a Kubernetes API server.
+const kubeRequestTimeout = 15 * time.Second
+
+// kubeClientConfig loads the kubeconfig the way kubectl does, honouring
+// KUBECONFIG, and selects contextName instead of the current context when
+// it is set.
+func kubeClientConfig(contextName string) clientcmd.ClientConfig {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: contextName}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+}
+
+// getLocalClusterUID returns the UID of the kube-system namespace in the
+// given kubeconfig context, which Komodor reports as the cluster ID.
+func getLocalClusterUID(ctx context.Context, contextName string) (string, error) {
+	restConfig, err := kubeClientConfig(contextName).ClientConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	restConfig.Timeout = kubeRequestTimeout
+
+	httpClient, err := rest.HTTPClientFor(restConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to build Kubernetes client: %w", err)
+	}
+	serverURL, _, err := rest.DefaultServerUrlFor(restConfig)
+	if err != nil {
+		return "", fmt.Errorf("invalid Kubernetes server URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, serverURL.JoinPath("/api/v1/namespaces/kube-system").String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster UID: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read kube-system namespace: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get cluster UID: kube-system namespace returned HTTP %d", resp.StatusCode)
+	}
+
+	var namespace struct {
+		Metadata struct {
+			UID string `json:"uid"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(body, &namespace); err != nil {
+		return "", fmt.Errorf("failed to parse kube-system namespace: %w", err)
+	}
+	if namespace.Metadata.UID == "" {
+		return "", fmt.Errorf("cluster UID not found in kube-system namespace metadata")
+	}
+
+	return namespace.Metadata.UID, nil
+}
+
+// kubeContextForCluster finds the kubeconfig context for a cluster given by
+// name: a context with that name, or else the only context using a cluster
+// entry with that name. It returns "" when there is no single such context.
+func kubeContextForCluster(clusterName string) string {
+	rawConfig, err := kubeClientConfig("").RawConfig()
+	if err != nil {
+		logMessage("⚠️  Could not load kubeconfig: %v", err)
+		return ""
+	}
+	if _, ok := rawConfig.Contexts[clusterName]; ok {
+		return clusterName
+	}
+
+	found := ""
+	for name, kubeContext := range rawConfig.Contexts {
+		if kubeContext.Cluster != clusterName {
+			continue
+		}
+		if found != "" {
+			return ""
+		}
+		found = name
+	}
+	return found
+}
+
+// kubeServerURL returns the API server the given context points at.
+func kubeServerURL(contextName string) (string, error) {
+	rawConfig, err := kubeClientConfig(contextName).RawConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	if contextName == "" {
+		contextName = rawConfig.CurrentContext
+	}
+	kubeContext, ok := rawConfig.Contexts[contextName]
+	if !ok {
+		return "", fmt.Errorf("context %q not found in kubeconfig", contextName)
+	}
+	cluster, ok := rawConfig.Clusters[kubeContext.Cluster]
+	if !ok {
+		return "", fmt.Errorf("cluster %q of context %q not found in kubeconfig", kubeContext.Cluster, contextName)
+	}
+	return cluster.Server, nil
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
+
+	"k9s-rca/komodor"
 )
 
 var (
@@ -21,10 +25,21 @@ type Config struct {
 	LocalClusterName   string
 	KomodorClusterName string
 	KomodorBaseURL     string
+	KomodorWebURL      string
 	Namespace          string
 	Name               string
 	Kind               string
 	Context            string
+	ClusterSelector    string
+	Client             *komodor.Client
+	Retry              komodor.RetryPolicy
+	PollInterval       time.Duration
+	ReuseWindow        time.Duration
+	ClusterCacheTTL    time.Duration
+	ForceNew           bool
+	Stream             bool
+	ExportFormat       string
+	ExportFile         string
 	TUI                TUI
 	Debug              bool
 }
@@ -48,23 +63,46 @@ func main() {
 		Long:    "A Go-based plugin for triggering Komodor Root Cause Analysis from K9s",
 		Version: fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, date),
 		RunE:    runRCA,
+		// main reports errors itself; runtime failures should not print usage.
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			debugMode, _ = cmd.Flags().GetBool("debug")
+		},
 	}
 
 	rootCmd.Flags().String("kind", "", "Kubernetes resource kind (Pod, Deployment, Service, etc.)")
 	rootCmd.Flags().String("namespace", "", "Kubernetes namespace")
 	rootCmd.Flags().String("name", "", "Kubernetes resource name")
-	rootCmd.Flags().String("api-key", "", "Komodor API key")
+	rootCmd.PersistentFlags().String("api-key", "", "Komodor API key")
 	rootCmd.Flags().String("cluster", "", "Kubernetes cluster name")
 	rootCmd.Flags().String("context", "", "Kubernetes context name")
-	rootCmd.Flags().String("base-url", "https://api.komodor.com", "Komodor API base URL")
+	rootCmd.Flags().String("cluster-selector", "", "Komodor cluster tags to match the local cluster by, e.g. env=prod,region=eu-west-1 (default: the selector saved with 'clusters select')")
+	rootCmd.PersistentFlags().String("base-url", komodor.DefaultBaseURL, "Komodor API base URL")
 	rootCmd.Flags().Bool("poll", false, "Poll for RCA completion")
 	rootCmd.Flags().Bool("background", false, "Run in background mode")
-	rootCmd.Flags().Bool("debug", false, "Enable debug logging")
-
-	if err := rootCmd.Execute(); err != nil {
+	rootCmd.Flags().Duration("reuse-window", 0, "Reattach to a finished session for the same resource if it completed within this window (default 0: only reattach to running sessions)")
+	rootCmd.Flags().Bool("new", false, "Always start a new session instead of reattaching to an existing one")
+	rootCmd.PersistentFlags().Bool("debug", false, "Enable debug logging")
+	rootCmd.PersistentFlags().Duration("poll-interval", 2*time.Second, "Delay between session polls")
+	rootCmd.PersistentFlags().Bool("stream", true, "Follow sessions over a live event stream, falling back to polling when it is unavailable")
+	rootCmd.PersistentFlags().Int("max-retries", komodor.DefaultRetryPolicy().MaxRetries, "Consecutive transient poll failures tolerated before giving up")
+	rootCmd.PersistentFlags().Duration("retry-max-wait", komodor.DefaultRetryPolicy().MaxWait, "Upper bound for a single retry backoff")
+	rootCmd.PersistentFlags().Duration("cluster-cache-ttl", time.Hour, "How long the Komodor cluster list is cached; mapped clusters are checked against it (0 fetches it on every run)")
+	rootCmd.PersistentFlags().String("web-url", "", "Komodor web app URL of a session, with {session} where the session ID goes; session links are hidden unless set")
+	addOutputFlags(rootCmd)
+	addExportFlags(rootCmd)
+	rootCmd.PersistentFlags().Duration("timeout", 0, "Abort all work after this duration, e.g. 10m (0 disables)")
+
+	rootCmd.AddCommand(newStatusCmd(), newWatchCmd(), newCancelCmd(), newCompareCmd(), newHistoryCmd(), newClustersCmd())
+
+	ctx, stop := signalContext()
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
 		logMessage("FATAL: Command execution failed: %v", err)
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCodeFor(err))
 	}
 }
 
@@ -79,9 +117,17 @@ func loadEnvironmentFiles() {
 }
 
 func runRCA(cmd *cobra.Command, args []string) error {
-	debugMode, _ = cmd.Flags().GetBool("debug")
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	return withContextCause(ctx, startRCA(ctx, cmd))
+}
 
-	tui := NewBubbleTeaTUI()
+func startRCA(ctx context.Context, cmd *cobra.Command) error {
+	tui, err := newTUI(cmd)
+	if err != nil {
+		return err
+	}
 
 	config, err := loadConfig(cmd, tui)
 	if err != nil {
@@ -90,65 +136,81 @@ func runRCA(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Validate before resolving the cluster, which may ask the user to pick
+	// one and save the choice.
 	if err := validateConfig(config); err != nil {
 		logMessage("FATAL: Validation error: %v", err)
 		tui.DisplayError("Validation error", err)
 		return err
 	}
 
+	if err := resolveConfigCluster(ctx, config); err != nil {
+		logMessage("FATAL: Configuration error: %v", err)
+		tui.DisplayError("Configuration error", err)
+		return err
+	}
+
 	logMessage("Config: APIKey=%s, Cluster=%s, BaseURL=%s, Namespace=%s, Name=%s, Kind=%s, Context=%s",
 		maskAPIKey(config.KomodorAPIKey), config.KomodorClusterName, config.KomodorBaseURL,
 		config.Namespace, config.Name, config.Kind, config.Context)
 
-	logMessage("🚀 Triggering RCA for %s: %s in namespace: %s on cluster: %s",
-		config.Kind, config.Name, config.Namespace, config.KomodorClusterName)
-
-	session, err := triggerRCA(config)
-	if err != nil {
-		logMessage("FATAL: RCA trigger failed: %v", err)
-		config.TUI.DisplayError("RCA trigger failed", err)
-		return fmt.Errorf("failed to trigger RCA: %w", err)
+	sessionID, resumed := "", false
+	if !config.ForceNew {
+		sessionID, resumed = findResumableSession(ctx, config)
 	}
 
-	if session.SessionID == "" {
-		logMessage("FATAL: No session ID received from Komodor API")
-		config.TUI.DisplayError("No session ID received from Komodor API", fmt.Errorf("empty session ID"))
-		return fmt.Errorf("no session ID received from Komodor API")
-	}
+	if resumed {
+		logMessage("🔁 Reattaching to RCA session %s for %s: %s in namespace: %s",
+			sessionID, config.Kind, config.Name, config.Namespace)
+		config.TUI.DisplayMessage(fmt.Sprintf("🔁 Reattaching to RCA session %s (use --new to start a new one)", sessionID))
+	} else {
+		logMessage("🚀 Triggering RCA for %s: %s in namespace: %s on cluster: %s",
+			config.Kind, config.Name, config.Namespace, config.KomodorClusterName)
+
+		session, err := config.Client.CreateSession(ctx, config.rcaSession())
+		if err != nil {
+			logMessage("FATAL: RCA trigger failed: %v", err)
+			config.TUI.DisplayError("RCA trigger failed", err)
+			return fmt.Errorf("failed to trigger RCA: %w", err)
+		}
+
+		if session.SessionID == "" {
+			logMessage("FATAL: No session ID received from Komodor API")
+			config.TUI.DisplayError("No session ID received from Komodor API", fmt.Errorf("empty session ID"))
+			return fmt.Errorf("no session ID received from Komodor API")
+		}
 
-	logMessage("\n✅ RCA triggered successfully! Session ID: %s", session.SessionID)
+		sessionID = session.SessionID
+		logMessage("\n✅ RCA triggered successfully! Session ID: %s", sessionID)
+		recordTriggeredSession(config, sessionID)
+	}
 
 	shouldPoll, _ := cmd.Flags().GetBool("poll")
 	isBackground, _ := cmd.Flags().GetBool("background")
 
 	if shouldPoll || !isBackground {
 		if bubbleTUI, ok := config.TUI.(*BubbleTeaTUI); ok {
-			return bubbleTUI.MonitorRCA(config, session.SessionID)
+			return bubbleTUI.MonitorRCA(ctx, config, sessionID)
 		}
-		return fmt.Errorf("TUI not properly initialized")
+		return pollRCAResults(ctx, config, sessionID)
 	}
 
+	config.TUI.DisplayMessage(fmt.Sprintf("RCA session %s started. Follow it with: k9s-rca watch %s", sessionID, sessionID))
 	return nil
 }
 
+// loadConfig reads the settings for triggering an RCA. The Komodor cluster
+// is filled in later by resolveConfigCluster.
 func loadConfig(cmd *cobra.Command, tui TUI) (*Config, error) {
-	debug, _ := cmd.Flags().GetBool("debug")
-
-	config := &Config{
-		KomodorAPIKey:    getEnvOrFlag(cmd, "KOMODOR_API_KEY", "api-key"),
-		KomodorBaseURL:   getEnvOrFlag(cmd, "KOMODOR_BASE_URL", "base-url"),
-		Namespace:        getEnvOrFlag(cmd, "NAMESPACE", "namespace"),
-		Name:             getEnvOrFlag(cmd, "NAME", "name"),
-		Kind:             getEnvOrFlag(cmd, "KIND", "kind"),
-		Context:          getEnvOrFlag(cmd, "CONTEXT", "context"),
-		LocalClusterName: getEnvOrFlag(cmd, "CLUSTER", "cluster"),
-		TUI:              tui,
-		Debug:            debug,
-	}
-
-	if config.KomodorBaseURL == "" {
-		config.KomodorBaseURL = "https://api.komodor.com"
-	}
+	config := loadAPIConfig(cmd, tui)
+	config.Namespace = getEnvOrFlag(cmd, "NAMESPACE", "namespace")
+	config.Name = getEnvOrFlag(cmd, "NAME", "name")
+	config.Kind = getEnvOrFlag(cmd, "KIND", "kind")
+	config.Context = getEnvOrFlag(cmd, "CONTEXT", "context")
+	config.LocalClusterName = getEnvOrFlag(cmd, "CLUSTER", "cluster")
+	config.ClusterSelector, _ = cmd.Flags().GetString("cluster-selector")
+	config.ReuseWindow, _ = cmd.Flags().GetDuration("reuse-window")
+	config.ForceNew, _ = cmd.Flags().GetBool("new")
 
 	if config.Context != "" && config.Context != config.LocalClusterName {
 		config.LocalClusterName = config.Context
@@ -158,15 +220,59 @@ func loadConfig(cmd *cobra.Command, tui TUI) (*Config, error) {
 		logMessage("ERROR: No cluster provided")
 		return nil, fmt.Errorf("cluster is required (use --cluster flag or CLUSTER env var)")
 	}
+	return config, nil
+}
 
-	komodorCluster, err := resolveKomodorCluster(config.KomodorAPIKey, config.KomodorBaseURL, config.LocalClusterName)
+// resolveConfigCluster sets the Komodor cluster for the configured local
+// cluster, letting the user pick one when it cannot be matched.
+func resolveConfigCluster(ctx context.Context, config *Config) error {
+	komodorCluster, err := resolveKomodorCluster(ctx, config)
+	var unresolved *unresolvedClusterError
+	if errors.As(err, &unresolved) && len(unresolved.Clusters) > 0 && canPickCluster(config) {
+		logMessage("Asking the user to pick a Komodor cluster: %v", err)
+		komodorCluster, err = pickKomodorCluster(ctx, unresolved)
+	}
 	if err != nil {
 		logMessage("ERROR: Failed to resolve Komodor cluster: %v", err)
-		return nil, err
+		return err
 	}
 	config.KomodorClusterName = komodorCluster
 	logMessage("Local cluster: %s, Komodor cluster: %s", config.LocalClusterName, config.KomodorClusterName)
-	return config, nil
+	return nil
+}
+
+// loadAPIConfig reads the settings shared by every command that talks to
+// the Komodor API, without resolving a cluster.
+func loadAPIConfig(cmd *cobra.Command, tui TUI) *Config {
+	debug, _ := cmd.Flags().GetBool("debug")
+
+	config := &Config{
+		KomodorAPIKey:  getEnvOrFlag(cmd, "KOMODOR_API_KEY", "api-key"),
+		KomodorBaseURL: getEnvOrFlag(cmd, "KOMODOR_BASE_URL", "base-url"),
+		KomodorWebURL:  getEnvOrFlag(cmd, "KOMODOR_WEB_URL", "web-url"),
+		TUI:            tui,
+		Debug:          debug,
+	}
+
+	config.Retry = komodor.DefaultRetryPolicy()
+	if maxRetries, err := cmd.Flags().GetInt("max-retries"); err == nil {
+		config.Retry.MaxRetries = maxRetries
+	}
+	if maxWait, err := cmd.Flags().GetDuration("retry-max-wait"); err == nil {
+		config.Retry.MaxWait = maxWait
+	}
+	config.PollInterval, _ = cmd.Flags().GetDuration("poll-interval")
+	config.ClusterCacheTTL, _ = cmd.Flags().GetDuration("cluster-cache-ttl")
+	config.Stream, _ = cmd.Flags().GetBool("stream")
+	config.ExportFormat, _ = cmd.Flags().GetString("export")
+	config.ExportFile, _ = cmd.Flags().GetString("export-file")
+
+	if config.KomodorBaseURL == "" {
+		config.KomodorBaseURL = komodor.DefaultBaseURL
+	}
+	config.Client = newKomodorClient(config)
+
+	return config
 }
 
 func getEnvOrFlag(cmd *cobra.Command, envVar, flagName string) string {
@@ -180,8 +286,8 @@ func getEnvOrFlag(cmd *cobra.Command, envVar, flagName string) string {
 }
 
 func validateConfig(config *Config) error {
-	if config.KomodorAPIKey == "" {
-		return fmt.Errorf("KOMODOR_API_KEY environment variable is required")
+	if err := validateAPIConfig(config); err != nil {
+		return err
 	}
 	if config.Namespace == "" {
 		return fmt.Errorf("namespace is required (use --namespace flag)")
@@ -195,6 +301,30 @@ func validateConfig(config *Config) error {
 	return nil
 }
 
+func validateAPIConfig(config *Config) error {
+	if config.KomodorAPIKey == "" {
+		return fmt.Errorf("KOMODOR_API_KEY environment variable is required")
+	}
+	if config.PollInterval <= 0 {
+		return fmt.Errorf("poll interval must be positive (use --poll-interval flag)")
+	}
+	if config.Retry.MaxRetries < 0 {
+		return fmt.Errorf("max retries cannot be negative (use --max-retries flag)")
+	}
+	if config.Retry.MaxWait <= 0 {
+		return fmt.Errorf("retry max wait must be positive (use --retry-max-wait flag)")
+	}
+	if config.Retry.MaxWait < config.Retry.BaseDelay {
+		return fmt.Errorf("retry max wait cannot be shorter than the first retry delay of %s (use --retry-max-wait flag)", config.Retry.BaseDelay)
+	}
+	if config.ExportFormat != "" {
+		if _, err := exportExtension(config.ExportFormat); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func maskAPIKey(apiKey string) string {
 	if apiKey == "" {
 		return "(empty)"
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"k9s-rca/komodor"
+)
+
+func TestValidateAPIConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string
+	}{
+		{name: "defaults", modify: func(*Config) {}},
+		{name: "no API key", modify: func(c *Config) { c.KomodorAPIKey = "" }, wantErr: "KOMODOR_API_KEY"},
+		{name: "zero poll interval", modify: func(c *Config) { c.PollInterval = 0 }, wantErr: "--poll-interval"},
+		{name: "negative max retries", modify: func(c *Config) { c.Retry.MaxRetries = -1 }, wantErr: "--max-retries"},
+		{name: "zero retry max wait", modify: func(c *Config) { c.Retry.MaxWait = 0 }, wantErr: "--retry-max-wait"},
+		{name: "negative retry max wait", modify: func(c *Config) { c.Retry.MaxWait = -time.Second }, wantErr: "--retry-max-wait"},
+		{name: "retry max wait below first delay", modify: func(c *Config) { c.Retry.MaxWait = time.Second }, wantErr: "--retry-max-wait"},
+		{name: "retry max wait equal to first delay", modify: func(c *Config) { c.Retry.MaxWait = c.Retry.BaseDelay }},
+		{name: "unknown export format", modify: func(c *Config) { c.ExportFormat = "pdf" }, wantErr: "pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				KomodorAPIKey: "key",
+				PollInterval:  2 * time.Second,
+				Retry:         komodor.DefaultRetryPolicy(),
+			}
+			tt.modify(config)
+
+			err := validateAPIConfig(config)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("validateAPIConfig() error = %v, want nil", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("validateAPIConfig() error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"k9s-rca/komodor"
+)
+
+const (
+	outputJSON   = "json"
+	outputYAML   = "yaml"
+	outputNDJSON = "ndjson"
+)
+
+// sessionDocument is what the json and yaml output modes print.
+type sessionDocument struct {
+	komodor.RCAPollResponse
+	Status string                 `json:"status"`
+	Raw    map[string]interface{} `json:"raw,omitempty"`
+}
+
+// outputEvent is a single NDJSON line.
+type outputEvent struct {
+	Time      time.Time   `json:"time"`
+	Type      string      `json:"type"`
+	SessionID string      `json:"sessionId,omitempty"`
+	Index     int         `json:"index,omitempty"`
+	Message   string      `json:"message,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// OutputTUI is a non-interactive TUI for scripts and CI. json and yaml print
+// one document once monitoring ends; ndjson streams an event per change.
+type OutputTUI struct {
+	format     string
+	includeRaw bool
+	out        io.Writer
+	errOut     io.Writer
+
+	previous *komodor.RCAPollResponse
+	last     *komodor.RCAPollResponse
+	printed  bool
+}
+
+func NewOutputTUI(format string, includeRaw bool, out, errOut io.Writer) (*OutputTUI, error) {
+	switch format {
+	case outputJSON, outputYAML, outputNDJSON:
+	default:
+		return nil, fmt.Errorf("unsupported output format %q (use json, yaml or ndjson)", format)
+	}
+
+	return &OutputTUI{
+		format:     format,
+		includeRaw: includeRaw,
+		out:        out,
+		errOut:     errOut,
+	}, nil
+}
+
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", "", "Print results non-interactively: json, yaml or ndjson")
+	cmd.Flags().Bool("raw", false, "Include the raw API payload in json/yaml output")
+}
+
+// newTUI picks the interactive Bubble Tea UI unless --output was given.
+func newTUI(cmd *cobra.Command) (TUI, error) {
+	format, _ := cmd.Flags().GetString("output")
+	if format == "" {
+		return NewBubbleTeaTUI(), nil
+	}
+
+	includeRaw, _ := cmd.Flags().GetBool("raw")
+	return NewOutputTUI(format, includeRaw, os.Stdout, os.Stderr)
+}
+
+func (o *OutputTUI) ClearScreen() {
+}
+
+func (o *OutputTUI) DisplayLiveRCAResults(results *komodor.RCAPollResponse, pollCount int) {
+	o.last = results
+	if o.format == outputNDJSON {
+		o.emitChanges(results)
+	}
+}
+
+func (o *OutputTUI) DisplayFinalRCAResults(results *komodor.RCAPollResponse) {
+	o.last = results
+	if o.format == outputNDJSON {
+		o.emitChanges(results)
+	}
+}
+
+func (o *OutputTUI) DisplayError(message string, err error) {
+	if o.format == outputNDJSON {
+		o.emit(outputEvent{Type: "error", Message: fmt.Sprintf("%s: %v", message, err)})
+		return
+	}
+	fmt.Fprintf(o.errOut, "%s: %v\n", message, err)
+}
+
+func (o *OutputTUI) DisplayMessage(message string) {
+	if message == "" {
+		return
+	}
+	fmt.Fprintln(o.errOut, message)
+}
+
+func (o *OutputTUI) DisplayProgressIndicator(message string) {
+}
+
+func (o *OutputTUI) WaitForExit() {
+	if o.format == outputNDJSON || o.printed || o.last == nil {
+		return
+	}
+	if err := writeSessionDocument(o.out, o.format, o.last, o.includeRaw); err != nil {
+		fmt.Fprintf(o.errOut, "failed to write output: %v\n", err)
+	}
+	o.printed = true
+}
+
+func (o *OutputTUI) emitChanges(results *komodor.RCAPollResponse) {
+	prev := o.previous
+	if prev == nil {
+		prev = &komodor.RCAPollResponse{}
+		o.emit(outputEvent{Type: "started", SessionID: results.SessionID})
+	}
+
+	for i := len(prev.Operations); i < len(results.Operations); i++ {
+		o.emit(outputEvent{Type: "operation", SessionID: results.SessionID, Index: i + 1, Data: results.Operations[i]})
+	}
+	for i := len(prev.WhatHappened); i < len(results.WhatHappened); i++ {
+		o.emit(outputEvent{Type: "whatHappened", SessionID: results.SessionID, Index: i + 1, Data: results.WhatHappened[i]})
+	}
+	for i := len(prev.EvidenceCollection); i < len(results.EvidenceCollection); i++ {
+		o.emit(outputEvent{Type: "evidence", SessionID: results.SessionID, Index: i + 1, Data: results.EvidenceCollection[i]})
+	}
+	if results.ProblemShort != prev.ProblemShort {
+		o.emit(outputEvent{Type: "problem", SessionID: results.SessionID, Data: results.ProblemShort})
+	}
+	if results.Recommendation != prev.Recommendation {
+		o.emit(outputEvent{Type: "recommendation", SessionID: results.SessionID, Data: results.Recommendation})
+	}
+
+	switch {
+	case results.IsComplete && !prev.IsComplete:
+		o.emit(outputEvent{Type: "completed", SessionID: results.SessionID, Data: o.document(results)})
+	case results.IsFailed && !prev.IsFailed:
+		o.emit(outputEvent{Type: "failed", SessionID: results.SessionID, Data: o.document(results)})
+	case results.IsStuck && !prev.IsStuck:
+		o.emit(outputEvent{Type: "stuck", SessionID: results.SessionID})
+	}
+
+	o.previous = results
+}
+
+func (o *OutputTUI) emit(event outputEvent) {
+	event.Time = time.Now().UTC()
+	line, err := json.Marshal(event)
+	if err != nil {
+		fmt.Fprintf(o.errOut, "failed to encode event: %v\n", err)
+		return
+	}
+	fmt.Fprintln(o.out, string(line))
+}
+
+func (o *OutputTUI) document(results *komodor.RCAPollResponse) sessionDocument {
+	return newSessionDocument(results, o.includeRaw)
+}
+
+func newSessionDocument(results *komodor.RCAPollResponse, includeRaw bool) sessionDocument {
+	doc := sessionDocument{RCAPollResponse: *results, Status: sessionState(results)}
+	if includeRaw {
+		doc.Raw = results.RawData
+	}
+	return doc
+}
+
+// writeSessionDocument prints results in the given format. The YAML output
+// is converted from JSON so both formats share field names and order.
+func writeSessionDocument(w io.Writer, format string, results *komodor.RCAPollResponse, includeRaw bool) error {
+	doc := newSessionDocument(results, includeRaw)
+
+	switch format {
+	case outputNDJSON:
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+
+	case outputYAML:
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return err
+		}
+		setBlockStyle(&node)
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(&node); err != nil {
+			return err
+		}
+		_, err = w.Write(buf.Bytes())
+		return err
+
+	default:
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+}
+
+// setBlockStyle undoes the flow style yaml.v3 keeps from JSON input.
+func setBlockStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle
+	if node.Kind == yaml.ScalarNode && node.Style&yaml.DoubleQuotedStyle != 0 && node.Tag == "!!str" {
+		node.Style &^= yaml.DoubleQuotedStyle
+		if strings.Contains(node.Value, "\n") {
+			node.Style |= yaml.LiteralStyle
+		}
+	}
+	for _, child := range node.Content {
+		setBlockStyle(child)
+	}
+}
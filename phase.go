@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+
+	"k9s-rca/komodor"
+)
+
+// analysisPhase is a best guess of where an RCA is, with a rough share of
+// the work done. Komodor does not report progress, so it is inferred from
+// which fields are populated and what the latest operation was doing.
+type analysisPhase struct {
+	Name     string
+	Progress float64
+}
+
+// operationPhases maps keywords in an operation to the phase they suggest.
+var operationPhases = []struct {
+	keywords []string
+	name     string
+}{
+	{[]string{"log"}, "Reading logs"},
+	{[]string{"event"}, "Checking events"},
+	{[]string{"metric", "cpu", "memory"}, "Checking metrics"},
+	{[]string{"deploy", "rollout", "change", "diff"}, "Reviewing changes"},
+	{[]string{"config", "yaml", "manifest", "spec"}, "Inspecting configuration"},
+	{[]string{"node"}, "Checking nodes"},
+}
+
+func inferPhase(results *komodor.RCAPollResponse) analysisPhase {
+	switch {
+	case results.IsComplete:
+		return analysisPhase{"Complete", 1}
+	case results.IsFailed:
+		return analysisPhase{"Failed", 1}
+	case results.Recommendation != "":
+		return analysisPhase{"Finalizing", 0.9}
+	case results.ProblemShort != "":
+		return analysisPhase{"Forming recommendation", 0.8}
+	case len(results.EvidenceCollection) > 0:
+		return analysisPhase{"Analyzing evidence", 0.6}
+	case len(results.WhatHappened) > 0:
+		return analysisPhase{"Building timeline", 0.4}
+	case len(results.Operations) == 0:
+		return analysisPhase{"Starting", 0.05}
+	}
+
+	// Each operation nudges the bar forward until the timeline shows up.
+	progress := 0.1 + 0.02*float64(min(len(results.Operations), 10))
+	last := strings.ToLower(results.Operations[len(results.Operations)-1])
+	for _, phase := range operationPhases {
+		for _, keyword := range phase.keywords {
+			if strings.Contains(last, keyword) {
+				return analysisPhase{phase.name, progress}
+			}
+		}
+	}
+	return analysisPhase{"Investigating", progress}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"k9s-rca/komodor"
+)
+
+func sessionState(results *komodor.RCAPollResponse) string {
+	switch {
+	case results.IsComplete:
+		return "complete"
+	case results.IsFailed:
+		return "failed"
+	case results.IsStuck:
+		return "stuck"
+	default:
+		return "in progress"
+	}
+}
+
+// sessionStateError maps a failed or stuck session to the error that carries
+// its exit code.
+func sessionStateError(results *komodor.RCAPollResponse) error {
+	switch {
+	case results.IsComplete:
+		return nil
+	case results.IsFailed:
+		return fmt.Errorf("%w: session %s", errSessionFailed, results.SessionID)
+	case results.IsStuck:
+		return fmt.Errorf("%w: session %s", errSessionStuck, results.SessionID)
+	default:
+		return nil
+	}
+}
+
+// writeTextReport renders results as plain text, free of styling, so it can
+// be piped or pasted anywhere.
+func writeTextReport(w io.Writer, results *komodor.RCAPollResponse) error {
+	var s strings.Builder
+
+	fmt.Fprintf(&s, "Session ID: %s\n", results.SessionID)
+	fmt.Fprintf(&s, "Status:     %s\n", sessionState(results))
+
+	if results.ProblemShort != "" {
+		fmt.Fprintf(&s, "\nProblem\n  %s\n", results.ProblemShort)
+	}
+
+	if results.Recommendation != "" {
+		fmt.Fprintf(&s, "\nRecommendation\n  %s\n", results.Recommendation)
+	}
+
+	if len(results.WhatHappened) > 0 {
+		s.WriteString("\nWhat Happened\n")
+		for i, event := range results.WhatHappened {
+			fmt.Fprintf(&s, "  %d. %s\n", i+1, event)
+		}
+	}
+
+	if len(results.EvidenceCollection) > 0 {
+		s.WriteString("\nEvidence\n")
+		for i, evidence := range results.EvidenceCollection {
+			fmt.Fprintf(&s, "  %d. %s\n", i+1, evidence.Query)
+			for _, line := range strings.Split(strings.TrimRight(evidence.Snippet, "\n"), "\n") {
+				fmt.Fprintf(&s, "     %s\n", line)
+			}
+		}
+	}
+
+	if len(results.Operations) > 0 {
+		s.WriteString("\nOperations\n")
+		for i, operation := range results.Operations {
+			fmt.Fprintf(&s, "  %d. %s\n", i+1, operation)
+		}
+	}
+
+	_, err := io.WriteString(w, s.String())
+	return err
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+
+	"k9s-rca/komodor"
+)
+
+func newStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status <session-id>",
+		Short: "Print the current state of an RCA session",
+		Long: "Fetch an RCA session by ID and print it once. Exits with 2 if the " +
+			"session failed and 3 if it is stuck.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+
+			return withContextCause(ctx, printSessionStatus(ctx, cmd, args[0]))
+		},
+	}
+
+	addOutputFlags(cmd)
+	addExportFlags(cmd)
+	return cmd
+}
+
+func newWatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watch <session-id>",
+		Short: "Reopen the live RCA view for an existing session",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+
+			return withContextCause(ctx, watchSession(ctx, cmd, args[0]))
+		},
+	}
+
+	addOutputFlags(cmd)
+	addExportFlags(cmd)
+	return cmd
+}
+
+func newCancelCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel <session-id>",
+		Short: "Stop a running RCA session on the Komodor server",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+
+			return withContextCause(ctx, cancelSession(ctx, cmd, args[0]))
+		},
+	}
+}
+
+func cancelSession(ctx context.Context, cmd *cobra.Command, sessionID string) error {
+	tui := NewBubbleTeaTUI()
+	config := loadAPIConfig(cmd, tui)
+	if err := validateAPIConfig(config); err != nil {
+		tui.DisplayError("Validation error", err)
+		return err
+	}
+
+	logMessage("Cancelling RCA session %s", sessionID)
+	if err := config.Client.CancelSession(ctx, sessionID); err != nil {
+		logMessage("ERROR: Failed to cancel session %s: %v", sessionID, err)
+		if errors.Is(err, komodor.ErrCancelUnsupported) {
+			tui.DisplayError("This Komodor API cannot stop sessions", err)
+			return fmt.Errorf("failed to cancel RCA session: %w", err)
+		}
+		tui.DisplayError("Failed to cancel RCA session", err)
+		return fmt.Errorf("failed to cancel RCA session: %w", err)
+	}
+
+	recordSessionOutcome(sessionID, "cancelled")
+	tui.DisplayMessage(fmt.Sprintf("🛑 Stopped RCA session %s", sessionID))
+	return nil
+}
+
+func newCompareCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compare <session-a> <session-b>",
+		Short: "Compare two RCA results for the same resource side by side",
+		Long: "Show how the problem, recommendation, timeline and evidence changed between " +
+			"two RCA sessions, typically an earlier run and a re-run after a fix. Sessions " +
+			"that cannot be fetched are read from the local history.",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+
+			return withContextCause(ctx, compareSessions(ctx, cmd, args[0], args[1]))
+		},
+	}
+
+	cmd.Flags().Int("width", 0, "Width of the side-by-side view (default: terminal width)")
+	return cmd
+}
+
+func compareSessions(ctx context.Context, cmd *cobra.Command, beforeID, afterID string) error {
+	config := loadAPIConfig(cmd, NewBubbleTeaTUI())
+
+	before, err := loadSessionResults(ctx, config, beforeID)
+	if err != nil {
+		return err
+	}
+	after, err := loadSessionResults(ctx, config, afterID)
+	if err != nil {
+		return err
+	}
+
+	beforeEntry, beforeErr := loadHistoryEntry(beforeID)
+	afterEntry, afterErr := loadHistoryEntry(afterID)
+	if beforeErr == nil && afterErr == nil && beforeEntry.Resource() != afterEntry.Resource() {
+		fmt.Fprintf(os.Stderr, "⚠️  The sessions are for different resources: %s and %s\n", beforeEntry.Resource(), afterEntry.Resource())
+	}
+
+	width, _ := cmd.Flags().GetInt("width")
+	if width <= 0 {
+		width = terminalWidth()
+	}
+	fmt.Println(renderComparison(compareResults(before, after), width))
+	return nil
+}
+
+// loadSessionResults fetches a session from the API, falling back to the
+// copy in the local history.
+func loadSessionResults(ctx context.Context, config *Config, sessionID string) (*komodor.RCAPollResponse, error) {
+	fetchErr := fmt.Errorf("no API key to fetch it with")
+	if config.KomodorAPIKey != "" {
+		results, err := config.Client.GetSession(ctx, sessionID)
+		if err == nil {
+			recordSessionResults(sessionID, results)
+			return results, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		fetchErr = err
+	}
+
+	entry, err := loadHistoryEntry(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load RCA session %s: %w", sessionID, fetchErr)
+	}
+	logMessage("Using stored results for session %s: %v", sessionID, fetchErr)
+	return entry.Results()
+}
+
+// terminalWidth is the width of the terminal on stdout, or 120 when stdout
+// is not a terminal.
+func terminalWidth() int {
+	if width, _, err := term.GetSize(os.Stdout.Fd()); err == nil && width > 0 {
+		return width
+	}
+	return 120
+}
+
+func printSessionStatus(ctx context.Context, cmd *cobra.Command, sessionID string) error {
+	tui, err := newTUI(cmd)
+	if err != nil {
+		return err
+	}
+	config := loadAPIConfig(cmd, tui)
+	if err := validateAPIConfig(config); err != nil {
+		tui.DisplayError("Validation error", err)
+		return err
+	}
+
+	logMessage("Fetching status for RCA session %s", sessionID)
+	results, err := config.Client.GetSession(ctx, sessionID)
+	if err != nil {
+		logMessage("ERROR: Failed to fetch session %s: %v", sessionID, err)
+		tui.DisplayError("Failed to fetch RCA session", err)
+		return fmt.Errorf("failed to fetch RCA session: %w", err)
+	}
+
+	recordSessionResults(sessionID, results)
+	if entry, err := loadHistoryEntry(sessionID); err == nil {
+		applyHistoryResource(config, entry)
+	}
+	exportConfigured(config, results)
+
+	format, _ := cmd.Flags().GetString("output")
+	switch {
+	case config.ExportFormat != "" && config.ExportFile == "-":
+		// The exported report already went to stdout.
+	case format != "":
+		includeRaw, _ := cmd.Flags().GetBool("raw")
+		err = writeSessionDocument(os.Stdout, format, results, includeRaw)
+	default:
+		err = writeTextReport(os.Stdout, results)
+	}
+	if err != nil {
+		return err
+	}
+	return sessionStateError(results)
+}
+
+func watchSession(ctx context.Context, cmd *cobra.Command, sessionID string) error {
+	tui, err := newTUI(cmd)
+	if err != nil {
+		return err
+	}
+	config := loadAPIConfig(cmd, tui)
+	if err := validateAPIConfig(config); err != nil {
+		tui.DisplayError("Validation error", err)
+		return err
+	}
+
+	if entry, err := loadHistoryEntry(sessionID); err == nil {
+		applyHistoryResource(config, entry)
+	}
+
+	logMessage("Watching RCA session %s", sessionID)
+	if bubbleTUI, ok := tui.(*BubbleTeaTUI); ok {
+		return bubbleTUI.MonitorRCA(ctx, config, sessionID)
+	}
+	return pollRCAResults(ctx, config, sessionID)
+}
+
+func newHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history [session-id]",
+		Short: "Browse and reopen past RCA sessions",
+		Long: "Browse RCA sessions recorded in ~/.k9s-komodor-rca/history. Press / to " +
+			"search and Enter to reopen a result offline. Pass a session ID to open it directly.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := commandContext(cmd)
+			defer cancel()
+
+			return withContextCause(ctx, browseHistory(ctx, cmd, args))
+		},
+	}
+
+	cmd.Flags().String("namespace", "", "Only show sessions in this namespace")
+	cmd.Flags().String("kind", "", "Only show sessions for this resource kind")
+	cmd.Flags().String("cluster", "", "Only show sessions for this local or Komodor cluster")
+	cmd.Flags().Bool("plain", false, "Print a plain table instead of opening the browser")
+
+	return cmd
+}
+
+func browseHistory(ctx context.Context, cmd *cobra.Command, args []string) error {
+	tui := NewBubbleTeaTUI()
+	config := loadAPIConfig(cmd, tui)
+
+	if len(args) == 1 {
+		entry, err := loadHistoryEntry(args[0])
+		if err != nil {
+			return fmt.Errorf("session %s not found in history: %w", args[0], err)
+		}
+		return tui.ShowRCAResults(ctx, config, entry)
+	}
+
+	entries, err := loadHistory()
+	if err != nil {
+		return err
+	}
+	entries = filterHistory(entries, cmd)
+
+	if plain, _ := cmd.Flags().GetBool("plain"); plain {
+		return writeHistoryTable(os.Stdout, entries)
+	}
+
+	if len(entries) == 0 {
+		tui.DisplayMessage("No RCA sessions found in history.")
+		return nil
+	}
+
+	selectedID := ""
+	for {
+		entry, err := selectHistoryEntry(ctx, entries, selectedID)
+		if err != nil || entry == nil {
+			return err
+		}
+		selectedID = entry.SessionID
+
+		if err := tui.ShowRCAResults(ctx, config, entry); err != nil {
+			return err
+		}
+	}
+}
+
+func filterHistory(entries []*HistoryEntry, cmd *cobra.Command) []*HistoryEntry {
+	namespace, _ := cmd.Flags().GetString("namespace")
+	kind, _ := cmd.Flags().GetString("kind")
+	cluster, _ := cmd.Flags().GetString("cluster")
+
+	var filtered []*HistoryEntry
+	for _, entry := range entries {
+		if namespace != "" && entry.Namespace != namespace {
+			continue
+		}
+		if kind != "" && !strings.EqualFold(entry.Kind, kind) {
+			continue
+		}
+		if cluster != "" && entry.LocalCluster != cluster && entry.KomodorCluster != cluster {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+	return filtered
+}
+
+func writeHistoryTable(w io.Writer, entries []*HistoryEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SESSION ID\tSTATUS\tRESOURCE\tCLUSTER\tTRIGGERED")
+	for _, entry := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			entry.SessionID, entry.Status, entry.Resource(), entry.clusterLabel(), formatAge(entry.TriggeredAt))
+	}
+	return tw.Flush()
+}
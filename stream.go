@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"k9s-rca/komodor"
+)
+
+// sessionUpdate is one message from a session stream: a new snapshot, or,
+// when results is nil, the end of the stream and the error that ended it.
+type sessionUpdate struct {
+	results *komodor.RCAPollResponse
+	err     error
+}
+
+// streamStartedMsg hands a freshly opened stream to the model, together
+// with the function that closes it.
+type streamStartedMsg struct {
+	updates <-chan sessionUpdate
+	stop    context.CancelFunc
+}
+
+// streamMsg carries an update from the stream it was read from, so updates
+// from a stream that has since been replaced can be told apart.
+type streamMsg struct {
+	updates <-chan sessionUpdate
+	sessionUpdate
+}
+
+// streamSession follows a session over the API's event stream in the
+// background. The channel is closed after the final update.
+func streamSession(ctx context.Context, config *Config, sessionID string) <-chan sessionUpdate {
+	updates := make(chan sessionUpdate)
+	send := func(update sessionUpdate) error {
+		select {
+		case updates <- update:
+			return nil
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+
+	go func() {
+		defer close(updates)
+		err := config.Client.StreamSession(ctx, sessionID, func(results *komodor.RCAPollResponse) error {
+			return send(sessionUpdate{results: results})
+		})
+		send(sessionUpdate{err: err})
+	}()
+	return updates
+}
+
+// logStreamEnd records why a session stream stopped; monitoring carries on
+// by polling.
+func logStreamEnd(sessionID string, err error) {
+	switch {
+	case errors.Is(err, komodor.ErrStreamingUnsupported):
+		logMessage("Streaming is not available for session %s, falling back to polling: %v", sessionID, err)
+	case errors.Is(err, komodor.ErrStreamIdle):
+		logMessage("Stream for session %s went quiet, falling back to polling: %v", sessionID, err)
+	case err != nil:
+		logMessage("Stream for session %s failed, falling back to polling: %v", sessionID, err)
+	default:
+		logMessage("Stream for session %s closed by the server", sessionID)
+	}
+}
+
+// startStreamCmd opens a stream that lasts until the monitor would give up
+// on the session anyway; after that, polling reports the timeout.
+func startStreamCmd(ctx context.Context, config *Config, sessionID string, deadline time.Time) tea.Cmd {
+	return func() tea.Msg {
+		streamCtx, stop := context.WithDeadline(ctx, deadline)
+		return streamStartedMsg{updates: streamSession(streamCtx, config, sessionID), stop: stop}
+	}
+}
+
+func waitForUpdate(updates <-chan sessionUpdate) tea.Cmd {
+	return func() tea.Msg {
+		// A closed channel reads as a final update, so the stream always ends.
+		return streamMsg{updates: updates, sessionUpdate: <-updates}
+	}
+}
@@ -1,9 +1,11 @@
 package main
 
+import "k9s-rca/komodor"
+
 type TUI interface {
 	ClearScreen()
-	DisplayLiveRCAResults(results *RCAPollResponse, pollCount int)
-	DisplayFinalRCAResults(results *RCAPollResponse)
+	DisplayLiveRCAResults(results *komodor.RCAPollResponse, pollCount int)
+	DisplayFinalRCAResults(results *komodor.RCAPollResponse)
 	DisplayError(message string, err error)
 	DisplayMessage(message string)
 	DisplayProgressIndicator(message string)